@@ -5,6 +5,7 @@ package main
 import (
 	"fmt"
 
+	"github.com/mokiat/ggj2024/internal/game/render"
 	gameui "github.com/mokiat/ggj2024/internal/ui"
 	"github.com/mokiat/ggj2024/resources"
 	glapp "github.com/mokiat/lacking-native/app"
@@ -25,8 +26,9 @@ func runApplication() error {
 	locator := ui.WrappedLocator(resource.NewFSLocator(resources.UI))
 
 	gameController := game.NewController(registry, glgame.NewShaderCollection())
+	splitController := render.NewSplitController(gameController)
 	uiController := ui.NewController(locator, glui.NewShaderCollection(), func(w *ui.Window) {
-		gameui.BootstrapApplication(w, gameController)
+		gameui.BootstrapApplication(w, splitController)
 	})
 
 	cfg := glapp.NewConfig("GGJ", 1280, 800)
@@ -36,5 +38,5 @@ func runApplication() error {
 	cfg.SetVSync(true)
 	cfg.SetIcon("ui/images/icon.png")
 	cfg.SetLocator(locator)
-	return glapp.Run(cfg, app.NewLayeredController(gameController, uiController))
+	return glapp.Run(cfg, app.NewLayeredController(splitController, uiController))
 }
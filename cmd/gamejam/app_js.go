@@ -5,6 +5,7 @@ package main
 import (
 	"fmt"
 
+	"github.com/mokiat/ggj2024/internal/game/render"
 	gameui "github.com/mokiat/ggj2024/internal/ui"
 	"github.com/mokiat/ggj2024/resources"
 	jsapp "github.com/mokiat/lacking-js/app"
@@ -24,12 +25,13 @@ func runApplication() error {
 	}
 	resourceLocator := ui.WrappedLocator(resource.NewFSLocator(resources.UI))
 	gameController := game.NewController(registry, jsgame.NewShaderCollection())
+	splitController := render.NewSplitController(gameController)
 	uiController := ui.NewController(resourceLocator, jsui.NewShaderCollection(), func(w *ui.Window) {
-		gameui.BootstrapApplication(w, gameController)
+		gameui.BootstrapApplication(w, splitController)
 	})
 
 	cfg := jsapp.NewConfig("screen")
 	cfg.AddGLExtension("EXT_color_buffer_float")
 	cfg.SetFullscreen(false)
-	return jsapp.Run(cfg, app.NewLayeredController(gameController, uiController))
+	return jsapp.Run(cfg, app.NewLayeredController(splitController, uiController))
 }
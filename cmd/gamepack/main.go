@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/mokiat/lacking/data/pack"
 	"github.com/mokiat/lacking/game/asset"
@@ -20,24 +21,43 @@ func runTool() error {
 		return fmt.Errorf("failed to create registry: %w", err)
 	}
 
-	skybox := ensureResource(registry, "414eb145-3c3b-4d90-ad53-7aede66bc9c1", "cube_texture", "Skybox")
-	skyboxReflection := ensureResource(registry, "ba7fb3b4-20c3-44f6-89c0-e6e34607209f", "cube_texture", "Skybox Reflection")
-	skyboxRefraction := ensureResource(registry, "c233eac3-96fb-4b40-88c0-5e7f6bf564e1", "cube_texture", "Skybox Refraction")
-
 	modelWorld := ensureResource(registry, "90345c66-2194-4a2e-acea-04b21c2df048", "model", "World")
 	modelAirplane := ensureResource(registry, "41b37fbc-5428-477b-8c7a-8bb58ac34514", "model", "Airplane")
 	modelBall := ensureResource(registry, "61cbde74-436e-4306-b3cc-b0c2459dbecb", "model", "Ball")
 	modelCow := ensureResource(registry, "4d6c54e9-9152-4c35-8f33-8fd9f898b091", "model", "Cow")
 	modelBurst := ensureResource(registry, "988992d4-2661-468a-baf3-298b1f6764d7", "model", "Burst")
+	variantModels := make([]asset.Resource, len(modelVariants))
+	for i, variant := range modelVariants {
+		variantModels[i] = ensureResource(registry, variant.id, "model", variant.name)
+	}
+
+	for _, preset := range skyPresets {
+		if _, err := os.Stat(preset.imagePath); err != nil {
+			return fmt.Errorf("failed to find sky image of preset %q: %w", preset.levelPath, err)
+		}
+	}
 
-	levelWorld := ensureResource(registry, "21a3cecd-6d04-4fcf-9c9d-e210b97dad3f", "scene", "World")
-	levelWorld.AddDependency(skybox)
-	levelWorld.AddDependency(skyboxReflection)
-	levelWorld.AddDependency(skyboxRefraction)
-	levelWorld.AddDependency(modelWorld)
-	levelWorld.AddDependency(modelAirplane)
-	levelWorld.AddDependency(modelBall)
-	levelWorld.AddDependency(modelBurst)
+	skies := make([]skyResources, len(skyPresets))
+	for i, preset := range skyPresets {
+		sky := skyResources{
+			preset:     preset,
+			skybox:     ensureResource(registry, preset.skyboxID, "cube_texture", "Skybox"+preset.suffix),
+			reflection: ensureResource(registry, preset.reflectionID, "cube_texture", "Skybox Reflection"+preset.suffix),
+			refraction: ensureResource(registry, preset.refractionID, "cube_texture", "Skybox Refraction"+preset.suffix),
+			level:      ensureResource(registry, preset.levelID, "scene", "World"+preset.suffix),
+		}
+		sky.level.AddDependency(sky.skybox)
+		sky.level.AddDependency(sky.reflection)
+		sky.level.AddDependency(sky.refraction)
+		sky.level.AddDependency(modelWorld)
+		sky.level.AddDependency(modelAirplane)
+		sky.level.AddDependency(modelBall)
+		sky.level.AddDependency(modelBurst)
+		for _, model := range variantModels {
+			sky.level.AddDependency(model)
+		}
+		skies[i] = sky
+	}
 
 	if err := registry.Save(); err != nil {
 		return fmt.Errorf("error saving resources: %w", err)
@@ -46,34 +66,36 @@ func runTool() error {
 	packer := pack.NewPacker(registry)
 
 	// Cube Textures
-	packer.Pipeline(func(p *pack.Pipeline) {
-		equirectangularImage := p.OpenImageResource("resources/images/skybox.hdr")
-		cubeImage := p.BuildCubeImage(
-			pack.WithFrontImage(p.BuildCubeSideFromEquirectangular(pack.CubeSideFront, equirectangularImage)),
-			pack.WithRearImage(p.BuildCubeSideFromEquirectangular(pack.CubeSideRear, equirectangularImage)),
-			pack.WithLeftImage(p.BuildCubeSideFromEquirectangular(pack.CubeSideLeft, equirectangularImage)),
-			pack.WithRightImage(p.BuildCubeSideFromEquirectangular(pack.CubeSideRight, equirectangularImage)),
-			pack.WithTopImage(p.BuildCubeSideFromEquirectangular(pack.CubeSideTop, equirectangularImage)),
-			pack.WithBottomImage(p.BuildCubeSideFromEquirectangular(pack.CubeSideBottom, equirectangularImage)),
-		)
-
-		smallerCubeImage := p.ScaleCubeImage(cubeImage, 512)
-		p.SaveCubeTextureAsset(skybox, smallerCubeImage,
-			pack.WithFormat(asset.TexelFormatRGBA16F),
-		)
-
-		reflectionCubeImage := p.ScaleCubeImage(cubeImage, 128)
-		p.SaveCubeTextureAsset(skyboxReflection, reflectionCubeImage,
-			pack.WithFormat(asset.TexelFormatRGBA16F),
-		)
-
-		refractionCubeImage := p.BuildIrradianceCubeImage(reflectionCubeImage,
-			pack.WithSampleCount(50),
-		)
-		p.SaveCubeTextureAsset(skyboxRefraction, refractionCubeImage,
-			pack.WithFormat(asset.TexelFormatRGBA16F),
-		)
-	})
+	for _, sky := range skies {
+		packer.Pipeline(func(p *pack.Pipeline) {
+			equirectangularImage := p.OpenImageResource(sky.preset.imagePath)
+			cubeImage := p.BuildCubeImage(
+				pack.WithFrontImage(p.BuildCubeSideFromEquirectangular(pack.CubeSideFront, equirectangularImage)),
+				pack.WithRearImage(p.BuildCubeSideFromEquirectangular(pack.CubeSideRear, equirectangularImage)),
+				pack.WithLeftImage(p.BuildCubeSideFromEquirectangular(pack.CubeSideLeft, equirectangularImage)),
+				pack.WithRightImage(p.BuildCubeSideFromEquirectangular(pack.CubeSideRight, equirectangularImage)),
+				pack.WithTopImage(p.BuildCubeSideFromEquirectangular(pack.CubeSideTop, equirectangularImage)),
+				pack.WithBottomImage(p.BuildCubeSideFromEquirectangular(pack.CubeSideBottom, equirectangularImage)),
+			)
+
+			smallerCubeImage := p.ScaleCubeImage(cubeImage, 512)
+			p.SaveCubeTextureAsset(sky.skybox, smallerCubeImage,
+				pack.WithFormat(asset.TexelFormatRGBA16F),
+			)
+
+			reflectionCubeImage := p.ScaleCubeImage(cubeImage, 128)
+			p.SaveCubeTextureAsset(sky.reflection, reflectionCubeImage,
+				pack.WithFormat(asset.TexelFormatRGBA16F),
+			)
+
+			refractionCubeImage := p.BuildIrradianceCubeImage(reflectionCubeImage,
+				pack.WithSampleCount(50),
+			)
+			p.SaveCubeTextureAsset(sky.refraction, refractionCubeImage,
+				pack.WithFormat(asset.TexelFormatRGBA16F),
+			)
+		})
+	}
 
 	// Models
 	packer.Pipeline(func(p *pack.Pipeline) {
@@ -96,18 +118,87 @@ func runTool() error {
 		p.SaveModelAsset(modelBurst,
 			p.OpenGLTFResource("resources/models/burst.glb"),
 		)
+
+		for i, variant := range modelVariants {
+			p.SaveModelAsset(variantModels[i], tintedModel{
+				source:  p.OpenGLTFResource(variant.source),
+				variant: variant,
+			})
+		}
 	})
 
 	// Levels
 	packer.Pipeline(func(p *pack.Pipeline) {
-		p.SaveLevelAsset(levelWorld,
-			p.OpenLevelResource("resources/levels/world.json"),
-		)
+		for _, sky := range skies {
+			p.SaveLevelAsset(sky.level,
+				p.OpenLevelResource(sky.preset.levelPath),
+			)
+		}
 	})
 
 	return packer.RunParallel()
 }
 
+// skyPreset is a time of day for which the World scene is packed with a
+// separate sky. The suffix needs to match the one in data.LightingPresets.
+type skyPreset struct {
+	suffix    string
+	imagePath string
+	levelPath string
+
+	skyboxID     string
+	reflectionID string
+	refractionID string
+	levelID      string
+}
+
+var skyPresets = []skyPreset{
+	{
+		suffix:       "",
+		imagePath:    "resources/images/skybox.hdr",
+		levelPath:    "resources/levels/world.json",
+		skyboxID:     "414eb145-3c3b-4d90-ad53-7aede66bc9c1",
+		reflectionID: "ba7fb3b4-20c3-44f6-89c0-e6e34607209f",
+		refractionID: "c233eac3-96fb-4b40-88c0-5e7f6bf564e1",
+		levelID:      "21a3cecd-6d04-4fcf-9c9d-e210b97dad3f",
+	},
+	{
+		suffix:       " Dawn",
+		imagePath:    "resources/images/skybox-dawn.hdr",
+		levelPath:    "resources/levels/world-dawn.json",
+		skyboxID:     "fccca1ff-1999-4d3f-af73-ab13c90569b8",
+		reflectionID: "e8dc1c71-91a0-40de-af6c-e989f6188da3",
+		refractionID: "c34e7d64-3093-42e3-bb5f-c83bd15231c3",
+		levelID:      "1266b5f3-a999-4499-93d0-fdacaab38bc1",
+	},
+	{
+		suffix:       " Dusk",
+		imagePath:    "resources/images/skybox-dusk.hdr",
+		levelPath:    "resources/levels/world-dusk.json",
+		skyboxID:     "0f77ff3b-60b4-4fcb-95bf-c4b595adcb5c",
+		reflectionID: "20018206-d991-4c40-8218-36df1817300a",
+		refractionID: "b3fd7906-72ad-4b5b-b7dd-d52cd431fad2",
+		levelID:      "d9080f78-af3c-4f69-8879-79f976c08e2d",
+	},
+	{
+		suffix:       " Night",
+		imagePath:    "resources/images/skybox-night.hdr",
+		levelPath:    "resources/levels/world-night.json",
+		skyboxID:     "fb60ab5e-fa28-4825-aec8-c7ac52aa6678",
+		reflectionID: "e3d14f06-96e8-4cfa-aae4-c133e2d3dd02",
+		refractionID: "0cf8aed8-c68b-4e9a-97b7-0cae918b869a",
+		levelID:      "6b31e893-543a-4878-ba81-1ac95c74f2fe",
+	},
+}
+
+type skyResources struct {
+	preset     skyPreset
+	skybox     asset.Resource
+	reflection asset.Resource
+	refraction asset.Resource
+	level      asset.Resource
+}
+
 func ensureResource(registry asset.Registry, id, kind, name string) asset.Resource {
 	resource := registry.ResourceByID(id)
 	if resource == nil {
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"github.com/mokiat/gomath/sprec"
+	"github.com/mokiat/lacking/data/pack"
+)
+
+// modelVariant is a recoloured copy of a model. Cow archetypes use these
+// to look different from each other while sharing the same geometry.
+type modelVariant struct {
+	id     string
+	name   string
+	source string
+	tint   sprec.Vec4
+
+	// finish, when specified, replaces the metallic and roughness of all
+	// materials.
+	finish *materialFinish
+}
+
+type materialFinish struct {
+	metallic  float32
+	roughness float32
+}
+
+var (
+	goldFinish  = &materialFinish{metallic: 1.0, roughness: 0.3}
+	steelFinish = &materialFinish{metallic: 0.8, roughness: 0.5}
+)
+
+// The names need to match the ones in data.CowArchetypes.
+var modelVariants = []modelVariant{
+	{
+		id:     "411dc764-b76c-40e3-a269-72cc5c116e2d",
+		name:   "Cow Golden",
+		source: "resources/models/cow.glb",
+		tint:   sprec.NewVec4(1.0, 0.8, 0.25, 1.0),
+		finish: goldFinish,
+	},
+	{
+		id:     "d54f4f49-49f7-416c-bb3d-49ffe1632674",
+		name:   "Cow Armored",
+		source: "resources/models/cow.glb",
+		tint:   sprec.NewVec4(0.55, 0.6, 0.65, 1.0),
+		finish: steelFinish,
+	},
+	{
+		id:     "42519dbd-2eda-4320-a573-692033d359cb",
+		name:   "Cow Decoy",
+		source: "resources/models/cow.glb",
+		tint:   sprec.NewVec4(1.0, 0.55, 0.6, 1.0),
+	},
+	{
+		id:     "caede197-5f4f-4dc8-8dbf-2e7e1c9ee772",
+		name:   "Cow Giant",
+		source: "resources/models/cow.glb",
+		tint:   sprec.NewVec4(0.75, 0.6, 0.45, 1.0),
+	},
+	{
+		id:     "e0e39052-8b6b-4c70-99e9-855c1265bfee",
+		name:   "Burst Golden",
+		source: "resources/models/burst.glb",
+		tint:   sprec.NewVec4(1.0, 0.8, 0.25, 1.0),
+		finish: goldFinish,
+	},
+	{
+		id:     "15814c24-7566-44f1-a1ad-6bb1b7d56052",
+		name:   "Burst Armored",
+		source: "resources/models/burst.glb",
+		tint:   sprec.NewVec4(0.55, 0.6, 0.65, 1.0),
+		finish: steelFinish,
+	},
+	{
+		id:     "663a4b65-a049-4bc2-adb4-b7e490e24ea4",
+		name:   "Burst Decoy",
+		source: "resources/models/burst.glb",
+		tint:   sprec.NewVec4(0.35, 0.35, 0.35, 1.0),
+	},
+	{
+		id:     "be4b6ab5-6f79-4789-9b42-76c39fef78e5",
+		name:   "Burst Giant",
+		source: "resources/models/burst.glb",
+		tint:   sprec.NewVec4(0.75, 0.6, 0.45, 1.0),
+	},
+}
+
+// tintedModel applies a variant to the materials of a model. The source
+// needs to be opened for the variant alone, since its materials are
+// changed in place.
+type tintedModel struct {
+	source  pack.ModelProvider
+	variant modelVariant
+}
+
+func (m tintedModel) Model() *pack.Model {
+	model := m.source.Model()
+	for _, material := range model.Materials {
+		material.Color = sprec.NewVec4(
+			material.Color.X*m.variant.tint.X,
+			material.Color.Y*m.variant.tint.Y,
+			material.Color.Z*m.variant.tint.Z,
+			material.Color.W*m.variant.tint.W,
+		)
+		if finish := m.variant.finish; finish != nil {
+			material.Metallic = finish.metallic
+			material.Roughness = finish.roughness
+		}
+	}
+	return model
+}
@@ -0,0 +1,100 @@
+package data
+
+import "time"
+
+const achievementRecordsFile = "achievements.json"
+
+const (
+	// AchievementRulePopStreak is met by popping Count cows within Window.
+	AchievementRulePopStreak AchievementRule = "pop-streak"
+
+	// AchievementRuleCleanVictory is met by winning without rubbing any
+	// cow.
+	AchievementRuleCleanVictory AchievementRule = "clean-victory"
+
+	// AchievementRuleTimeLeft is met by winning a game with a countdown
+	// while at least Window is left on it.
+	AchievementRuleTimeLeft AchievementRule = "time-left"
+
+	// AchievementRuleInvertedPop is met by popping a cow while flying
+	// upside down.
+	AchievementRuleInvertedPop AchievementRule = "inverted-pop"
+)
+
+// AchievementRule identifies how an achievement is earned.
+type AchievementRule string
+
+// Achievement describes something notable that a player can do in a game.
+// Count and Window parameterize the rule.
+type Achievement struct {
+	ID          string
+	Title       string
+	Description string
+	Rule        AchievementRule
+	Count       int
+	Window      time.Duration
+}
+
+var Achievements = []Achievement{
+	{
+		ID:          "hat-trick",
+		Title:       "Hat Trick",
+		Description: "Pop 3 cows within 5 seconds.",
+		Rule:        AchievementRulePopStreak,
+		Count:       3,
+		Window:      5 * time.Second,
+	},
+	{
+		ID:          "gentle-pilot",
+		Title:       "Gentle Pilot",
+		Description: "Win without rubbing any cow.",
+		Rule:        AchievementRuleCleanVictory,
+	},
+	{
+		ID:          "ahead-of-schedule",
+		Title:       "Ahead of Schedule",
+		Description: "Finish with 60 seconds left on the clock.",
+		Rule:        AchievementRuleTimeLeft,
+		Window:      60 * time.Second,
+	},
+	{
+		ID:          "barrel-roll",
+		Title:       "Do a Barrel Roll",
+		Description: "Pop a cow while flying upside down.",
+		Rule:        AchievementRuleInvertedPop,
+	},
+}
+
+// AchievementRecord holds when an achievement was unlocked.
+type AchievementRecord struct {
+	ID         string    `json:"id"`
+	UnlockedAt time.Time `json:"unlocked_at"`
+}
+
+// LoadAchievementRecords reads the locally stored achievements, keyed by
+// ID. A missing file is not an error.
+func LoadAchievementRecords() (map[string]AchievementRecord, error) {
+	records := make(map[string]AchievementRecord)
+	if err := loadJSON(achievementRecordsFile, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// UnlockAchievement stores that the specified achievement was unlocked at
+// the specified time. Achievements that are already unlocked keep their
+// original time.
+func UnlockAchievement(id string, at time.Time) error {
+	records, err := LoadAchievementRecords()
+	if err != nil {
+		return err
+	}
+	if _, ok := records[id]; ok {
+		return nil
+	}
+	records[id] = AchievementRecord{
+		ID:         id,
+		UnlockedAt: at,
+	}
+	return saveJSON(achievementRecordsFile, records)
+}
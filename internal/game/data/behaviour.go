@@ -0,0 +1,115 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/mokiat/ggj2024/resources"
+)
+
+const (
+	CowBehaviourStatic CowBehaviour = "static"
+	CowBehaviourGraze  CowBehaviour = "graze"
+	CowBehaviourFlee   CowBehaviour = "flee"
+	CowBehaviourHerd   CowBehaviour = "herd"
+)
+
+type CowBehaviour string
+
+func (b CowBehaviour) valid() bool {
+	switch b {
+	case CowBehaviourStatic, CowBehaviourGraze, CowBehaviourFlee, CowBehaviourHerd:
+		return true
+	default:
+		return false
+	}
+}
+
+// CowBehaviours selects how the cows of a level move. It is read from the
+// level data in resources/gameplay, so that it can be changed without
+// touching the code.
+type CowBehaviours struct {
+	Default CowBehaviour `json:"default"`
+
+	// Nodes maps cow node names (e.g. Cow.001) to a behaviour that
+	// overrides the default one.
+	Nodes map[string]CowBehaviour `json:"nodes"`
+
+	// SpawnZones maps spawn zone names to the behaviour of the cows that
+	// are placed in the zone.
+	SpawnZones map[string]CowBehaviour `json:"spawn_zones"`
+}
+
+// Node returns the behaviour of the cow with the specified node name.
+func (b *CowBehaviours) Node(nodeName string) CowBehaviour {
+	if behaviour, ok := b.Nodes[nodeName]; ok {
+		return behaviour
+	}
+	return b.Default
+}
+
+// SpawnZone returns the behaviour of the cows in the specified zone.
+func (b *CowBehaviours) SpawnZone(zone *SpawnZone) CowBehaviour {
+	if behaviour, ok := b.SpawnZones[zone.Name]; ok {
+		return behaviour
+	}
+	return b.Default
+}
+
+// Moving returns whether any cow can leave the place where it is spawned.
+func (b *CowBehaviours) Moving() bool {
+	if b.Default != CowBehaviourStatic {
+		return true
+	}
+	for _, behaviours := range []map[string]CowBehaviour{b.Nodes, b.SpawnZones} {
+		for _, behaviour := range behaviours {
+			if behaviour != CowBehaviourStatic {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (b *CowBehaviours) validate(level *Level) error {
+	if !b.Default.valid() {
+		return fmt.Errorf("%w: %q has unknown default cow behaviour %q", ErrInvalidLevel, level.Name, b.Default)
+	}
+	for node, behaviour := range b.Nodes {
+		if !behaviour.valid() {
+			return fmt.Errorf("%w: %q has unknown cow behaviour %q for %q", ErrInvalidLevel, level.Name, behaviour, node)
+		}
+	}
+	for zone, behaviour := range b.SpawnZones {
+		if !behaviour.valid() {
+			return fmt.Errorf("%w: %q has unknown cow behaviour %q for spawn zone %q", ErrInvalidLevel, level.Name, behaviour, zone)
+		}
+		if !slices.ContainsFunc(level.SpawnZones, func(candidate SpawnZone) bool { return candidate.Name == zone }) {
+			return fmt.Errorf("%w: %q has cow behaviour for unknown spawn zone %q", ErrInvalidLevel, level.Name, zone)
+		}
+	}
+	return nil
+}
+
+// levelData is the part of a level that is kept in resources/gameplay.
+type levelData struct {
+	CowBehaviours CowBehaviours `json:"cow_behaviours"`
+}
+
+// LoadCowBehaviours reads the cow behaviours of the specified level from
+// its level data.
+func LoadCowBehaviours(level *Level) (*CowBehaviours, error) {
+	content, err := resources.Gameplay.ReadFile(fmt.Sprintf("gameplay/%s.json", level.Name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read level data: %w", err)
+	}
+	var data levelData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode level data: %w", err)
+	}
+	if err := data.CowBehaviours.validate(level); err != nil {
+		return nil, err
+	}
+	return &data.CowBehaviours, nil
+}
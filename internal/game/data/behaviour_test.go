@@ -0,0 +1,94 @@
+package data
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLevelCowBehaviours(t *testing.T) {
+	for name, level := range Levels {
+		behaviours, err := LoadCowBehaviours(level)
+		if err != nil {
+			t.Fatalf("%s: failed to load cow behaviours: %v", name, err)
+		}
+		if behaviours.Default == "" {
+			t.Errorf("%s: expected a default cow behaviour", name)
+		}
+	}
+}
+
+func TestCowBehavioursNode(t *testing.T) {
+	behaviours := &CowBehaviours{
+		Default: CowBehaviourGraze,
+		Nodes: map[string]CowBehaviour{
+			"Cow.001": CowBehaviourFlee,
+		},
+		SpawnZones: map[string]CowBehaviour{
+			"Pasture": CowBehaviourHerd,
+		},
+	}
+	if behaviour := behaviours.Node("Cow.001"); behaviour != CowBehaviourFlee {
+		t.Errorf("expected flee, got %q", behaviour)
+	}
+	if behaviour := behaviours.Node("Cow.002"); behaviour != CowBehaviourGraze {
+		t.Errorf("expected default graze, got %q", behaviour)
+	}
+	if behaviour := behaviours.SpawnZone(&SpawnZone{Name: "Pasture"}); behaviour != CowBehaviourHerd {
+		t.Errorf("expected herd, got %q", behaviour)
+	}
+	if behaviour := behaviours.SpawnZone(&SpawnZone{Name: "Meadow"}); behaviour != CowBehaviourGraze {
+		t.Errorf("expected default graze, got %q", behaviour)
+	}
+}
+
+func TestCowBehavioursMoving(t *testing.T) {
+	behaviours := &CowBehaviours{
+		Default: CowBehaviourStatic,
+	}
+	if behaviours.Moving() {
+		t.Errorf("expected static cows not to be moving")
+	}
+	behaviours.Nodes = map[string]CowBehaviour{
+		"Cow.001": CowBehaviourGraze,
+	}
+	if !behaviours.Moving() {
+		t.Errorf("expected a grazing cow to be moving")
+	}
+}
+
+func TestCowBehavioursValidate(t *testing.T) {
+	level := &Level{
+		Name: "test",
+		SpawnZones: []SpawnZone{
+			{Name: "Pasture"},
+		},
+	}
+	testCases := map[string]CowBehaviours{
+		"unknown default": {
+			Default: "dance",
+		},
+		"unknown node behaviour": {
+			Default: CowBehaviourGraze,
+			Nodes: map[string]CowBehaviour{
+				"Cow.001": "dance",
+			},
+		},
+		"unknown spawn zone behaviour": {
+			Default: CowBehaviourGraze,
+			SpawnZones: map[string]CowBehaviour{
+				"Pasture": "dance",
+			},
+		},
+		"unknown spawn zone": {
+			Default: CowBehaviourGraze,
+			SpawnZones: map[string]CowBehaviour{
+				"Meadow": CowBehaviourHerd,
+			},
+		},
+	}
+	for name, behaviours := range testCases {
+		if err := behaviours.validate(level); !errors.Is(err, ErrInvalidLevel) {
+			t.Errorf("%s: expected invalid level, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package data
+
+import (
+	"time"
+
+	"github.com/mokiat/gomath/dprec"
+)
+
+const (
+	BoundsShapeBox      BoundsShape = "box"
+	BoundsShapeCylinder BoundsShape = "cylinder"
+)
+
+type BoundsShape string
+
+const (
+	OutOfBoundsRespawn OutOfBoundsAction = "respawn"
+	OutOfBoundsDefeat  OutOfBoundsAction = "defeat"
+)
+
+// OutOfBoundsAction specifies what happens to a player that stays outside
+// of the level bounds for too long.
+type OutOfBoundsAction string
+
+// Bounds describes the volume that the player is allowed to fly in.
+type Bounds struct {
+	Shape  BoundsShape
+	Center dprec.Vec3
+
+	// HalfSize is the horizontal extent of a box shape along X and Z.
+	HalfSize dprec.Vec3
+
+	// Radius is the horizontal extent of a cylinder shape.
+	Radius float64
+
+	// Ceiling is the highest altitude that is still within bounds.
+	Ceiling float64
+
+	// Grace is how long the player can stay out of bounds before Action
+	// is taken.
+	Grace time.Duration
+
+	Action OutOfBoundsAction
+}
+
+// Contains returns whether the specified position is within bounds. Zero
+// Bounds contain everything.
+func (b Bounds) Contains(position dprec.Vec3) bool {
+	if b.Ceiling > 0.0 && position.Y > b.Ceiling {
+		return false
+	}
+	delta := dprec.Vec3Diff(position, b.Center)
+	switch b.Shape {
+	case BoundsShapeBox:
+		return dprec.Abs(delta.X) <= b.HalfSize.X && dprec.Abs(delta.Z) <= b.HalfSize.Z
+	case BoundsShapeCylinder:
+		return delta.X*delta.X+delta.Z*delta.Z <= b.Radius*b.Radius
+	default:
+		return true
+	}
+}
@@ -0,0 +1,149 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mokiat/lacking/audio"
+	"github.com/mokiat/lacking/game"
+	"github.com/mokiat/lacking/util/async"
+)
+
+var ErrUnknownLevel = errors.New("unknown level")
+
+func NewPlayCache(audioAPI audio.API, engine *game.Engine, resourceSet *game.ResourceSet) *PlayCache {
+	return &PlayCache{
+		audioAPI:    audioAPI,
+		engine:      engine,
+		resourceSet: resourceSet,
+		entries:     make(map[string]*playCacheEntry),
+	}
+}
+
+// PlayCache keeps the play data of levels loaded for as long as they are
+// referenced, so that restarting a level does not load its assets again.
+type PlayCache struct {
+	audioAPI    audio.API
+	engine      *game.Engine
+	resourceSet *game.ResourceSet
+
+	mu      sync.Mutex
+	entries map[string]*playCacheEntry
+}
+
+// Acquire returns the play data for the specified level and increments the
+// level's reference count. Every call needs to be paired with a Release.
+func (c *PlayCache) Acquire(levelName string, progress *LoadProgress) async.Promise[*PlayData] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[levelName]
+	if !ok {
+		level, ok := Levels[levelName]
+		if !ok {
+			err := fmt.Errorf("%w: %q", ErrUnknownLevel, levelName)
+			return async.NewFailedPromise[*PlayData](err)
+		}
+		if err := level.Validate(); err != nil {
+			return async.NewFailedPromise[*PlayData](err)
+		}
+		behaviours, err := LoadCowBehaviours(level)
+		if err != nil {
+			return async.NewFailedPromise[*PlayData](err)
+		}
+		entry = c.newEntry(level, behaviours)
+		c.entries[levelName] = entry
+	}
+	entry.refs++
+
+	// A failed load is not worth keeping around, since the assets that did
+	// load are also held by the failed resource set. Start over instead.
+	if entry.promise.Ready() {
+		if _, err := entry.promise.Wait(); err != nil {
+			refs := entry.refs
+			c.deleteEntry(entry)
+			entry = c.newEntry(entry.level, entry.behaviours)
+			entry.refs = refs
+			c.entries[levelName] = entry
+		}
+	}
+
+	// Each acquisition picks its own radio chatter, so the play data needs
+	// to be assembled again, though all assets are served from the entry.
+	entry.promise = c.load(entry, progress)
+	return entry.promise
+}
+
+// Release decrements the reference count of the specified level and frees
+// its assets once no longer referenced.
+func (c *PlayCache) Release(levelName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[levelName]
+	if !ok {
+		return
+	}
+	entry.refs--
+	if entry.refs <= 0 {
+		delete(c.entries, levelName)
+		c.deleteEntry(entry)
+	}
+}
+
+func (c *PlayCache) newEntry(level *Level, behaviours *CowBehaviours) *playCacheEntry {
+	return &playCacheEntry{
+		level:       level,
+		behaviours:  behaviours,
+		resourceSet: c.resourceSet.CreateResourceSet(),
+		sounds:      make(map[string]async.Promise[audio.Media]),
+		promise:     async.NewFailedPromise[*PlayData](errors.New("not loaded")),
+	}
+}
+
+func (c *PlayCache) deleteEntry(entry *playCacheEntry) {
+	entry.resourceSet.Delete()
+	sounds := entry.sounds
+	go func() {
+		for _, promise := range sounds {
+			if media, err := promise.Wait(); err == nil {
+				c.engine.IOWorker().Schedule(func() error {
+					media.Delete()
+					return nil
+				})
+			}
+		}
+	}()
+}
+
+// openSound returns the cached sound or starts loading it. It must be
+// called with the cache lock held.
+func (c *PlayCache) openSound(entry *playCacheEntry, progress *LoadProgress, name string) async.Promise[audio.Media] {
+	promise, ok := entry.sounds[name]
+	if !ok {
+		promise = loadSound(c.audioAPI, c.engine, name)
+		entry.sounds[name] = promise
+	}
+	return track(progress, name, promise)
+}
+
+// openTerrain returns the cached terrain of the level or starts loading
+// it. It must be called with the cache lock held.
+func (c *PlayCache) openTerrain(entry *playCacheEntry, progress *LoadProgress) async.Promise[*Terrain] {
+	if entry.terrain == nil {
+		promise := loadTerrain(c.engine, entry.level.SceneName())
+		entry.terrain = &promise
+	}
+	return track(progress, "Terrain", *entry.terrain)
+}
+
+type playCacheEntry struct {
+	level       *Level
+	behaviours  *CowBehaviours
+	refs        int
+	resourceSet *game.ResourceSet
+	sounds      map[string]async.Promise[audio.Media]
+	terrain     *async.Promise[*Terrain]
+	promise     async.Promise[*PlayData]
+}
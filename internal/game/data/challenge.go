@@ -0,0 +1,109 @@
+package data
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/mokiat/gomath/dprec"
+)
+
+const (
+	challengeCowCount       = 10
+	challengeLayoutAttempts = 10
+)
+
+// challengeArchetypes lists the archetypes that a challenge picks from.
+// Repeated entries are proportionally more likely.
+var challengeArchetypes = []string{
+	CowArchetypeRegular,
+	CowArchetypeRegular,
+	CowArchetypeRegular,
+	CowArchetypeRegular,
+	CowArchetypeGolden,
+	CowArchetypeArmored,
+	CowArchetypeDecoy,
+	CowArchetypeGiant,
+}
+
+// NewDailyChallenge returns the challenge for the day of the specified
+// time. The day is taken in UTC, so that everyone gets the same one.
+func NewDailyChallenge(now time.Time) *Challenge {
+	date := now.UTC().Format(time.DateOnly)
+	hash := fnv.New64a()
+	hash.Write([]byte(date))
+	return &Challenge{
+		Date: date,
+		Seed: int64(hash.Sum64()),
+	}
+}
+
+// Challenge derives a level layout from a seed, so that all players with
+// the same seed get the same game.
+type Challenge struct {
+	Date string
+	Seed int64
+}
+
+// CowLayout picks which of the candidate cow nodes get a cow and what
+// archetype each cow has.
+func (c *Challenge) CowLayout(candidates []string) map[string]*CowArchetype {
+	sorted := make([]string, len(candidates))
+	copy(sorted, candidates)
+	sort.Strings(sorted)
+
+	random := c.random(1)
+	random.Shuffle(len(sorted), func(i, j int) {
+		sorted[i], sorted[j] = sorted[j], sorted[i]
+	})
+
+	count := min(challengeCowCount, len(sorted))
+	result := make(map[string]*CowArchetype, count)
+	for attempt := 0; attempt < challengeLayoutAttempts; attempt++ {
+		var points int
+		for _, name := range sorted[:count] {
+			archetype := CowArchetypes[challengeArchetypes[random.Intn(len(challengeArchetypes))]]
+			result[name] = archetype
+			points += archetype.Points
+		}
+		// A layout without points would be won as soon as it starts.
+		if points > 0 || count == 0 {
+			return result
+		}
+	}
+	result[sorted[0]] = CowArchetypes[CowArchetypeRegular]
+	return result
+}
+
+// Wind returns a variation of the specified wind with a different
+// direction and strength.
+func (c *Challenge) Wind(base Wind) Wind {
+	random := c.random(2)
+	yaw := dprec.Degrees(random.Float64() * 360.0)
+	strength := 0.5 + random.Float64()
+
+	result := base
+	result.Velocity = dprec.Vec3Prod(dprec.QuatVec3Rotation(
+		dprec.RotationQuat(yaw, dprec.BasisYVec3()),
+		base.Velocity,
+	), strength)
+	result.GustStrength = base.GustStrength * strength
+	return result
+}
+
+func (c *Challenge) Payload() Payload {
+	random := c.random(3)
+	return Payloads[random.Intn(len(Payloads))]
+}
+
+// SpawnRandom returns the generator that places cows in spawn zones.
+func (c *Challenge) SpawnRandom() *rand.Rand {
+	return c.random(4)
+}
+
+// random returns a generator for one aspect of the challenge, so that
+// aspects do not affect each other.
+func (c *Challenge) random(aspect int64) *rand.Rand {
+	return rand.New(rand.NewSource(c.Seed ^ (aspect * 0x5DEECE66D)))
+}
@@ -0,0 +1,90 @@
+package data
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mokiat/gomath/dprec"
+)
+
+func TestDailyChallengeSeed(t *testing.T) {
+	morning := time.Date(2024, time.January, 26, 1, 0, 0, 0, time.UTC)
+	evening := time.Date(2024, time.January, 26, 23, 0, 0, 0, time.UTC)
+	tomorrow := time.Date(2024, time.January, 27, 1, 0, 0, 0, time.UTC)
+
+	challenge := NewDailyChallenge(morning)
+	if challenge.Date != "2024-01-26" {
+		t.Errorf("expected date 2024-01-26, got %q", challenge.Date)
+	}
+	if other := NewDailyChallenge(evening); other.Seed != challenge.Seed {
+		t.Errorf("expected the same seed during a day, got %d and %d", challenge.Seed, other.Seed)
+	}
+	if other := NewDailyChallenge(tomorrow); other.Seed == challenge.Seed {
+		t.Errorf("expected a different seed on the next day")
+	}
+
+	// Players in other time zones get the challenge of the UTC day.
+	tokyo := time.FixedZone("JST", 9*60*60)
+	if other := NewDailyChallenge(evening.In(tokyo)); other.Date != challenge.Date {
+		t.Errorf("expected date %q in another time zone, got %q", challenge.Date, other.Date)
+	}
+}
+
+func TestChallengeCowLayout(t *testing.T) {
+	candidates := make([]string, 20)
+	for i := range candidates {
+		candidates[i] = fmt.Sprintf("Cow.%03d", i+1)
+	}
+	reversed := make([]string, len(candidates))
+	for i, name := range candidates {
+		reversed[len(candidates)-1-i] = name
+	}
+
+	challenge := &Challenge{Seed: 42}
+	layout := challenge.CowLayout(candidates)
+	if len(layout) != challengeCowCount {
+		t.Fatalf("expected %d cows, got %d", challengeCowCount, len(layout))
+	}
+	points := 0
+	for _, archetype := range layout {
+		points += archetype.Points
+	}
+	if points == 0 {
+		t.Errorf("expected the layout to be worth points")
+	}
+
+	// The layout depends only on the seed, not on the order of the nodes.
+	other := (&Challenge{Seed: 42}).CowLayout(reversed)
+	if len(other) != len(layout) {
+		t.Fatalf("expected %d cows for the same seed, got %d", len(layout), len(other))
+	}
+	for name, archetype := range layout {
+		if other[name] != archetype {
+			t.Errorf("expected %s to be %s for the same seed", name, archetype.Name)
+		}
+	}
+
+	if few := challenge.CowLayout(candidates[:3]); len(few) != 3 {
+		t.Errorf("expected all 3 candidates to get a cow, got %d", len(few))
+	}
+}
+
+func TestChallengeWind(t *testing.T) {
+	base := Wind{
+		Velocity:     dprec.NewVec3(4.0, 0.0, 0.0),
+		GustStrength: 2.0,
+	}
+
+	first := (&Challenge{Seed: 7}).Wind(base)
+	second := (&Challenge{Seed: 7}).Wind(base)
+	if first.Velocity != second.Velocity || first.GustStrength != second.GustStrength {
+		t.Errorf("expected the same wind for the same seed, got %+v and %+v", first, second)
+	}
+	if speed := first.Velocity.Length(); speed < 2.0 || speed > 6.0 {
+		t.Errorf("expected wind speed within [2, 6], got %f", speed)
+	}
+	if first.GustStrength < base.GustStrength*0.5 || first.GustStrength > base.GustStrength*1.5 {
+		t.Errorf("expected gust strength within [1, 3], got %f", first.GustStrength)
+	}
+}
@@ -0,0 +1,114 @@
+package data
+
+import "time"
+
+const (
+	CowArchetypeRegular = "regular"
+	CowArchetypeGolden  = "golden"
+	CowArchetypeArmored = "armored"
+	CowArchetypeDecoy   = "decoy"
+	CowArchetypeGiant   = "giant"
+)
+
+// CowArchetype describes how a type of cow looks and how it reacts to
+// being hit by the ball.
+type CowArchetype struct {
+	Name            string
+	Model           string
+	Scale           float64
+	CollisionRadius float64
+
+	// PopImpactSpeed is the relative speed between the ball and the cow
+	// that is needed for a contact to count as a hit. Slower contacts only
+	// make the cow wobble.
+	PopImpactSpeed float64
+
+	// Hits is the number of ball hits that are needed to pop the cow.
+	Hits int
+
+	// MinImpactSpeed, when positive, pops the cow on a single contact that
+	// is at least this fast, regardless of Hits.
+	MinImpactSpeed float64
+
+	// Points is the amount that popping the cow contributes towards the
+	// required cow count.
+	Points int
+
+	// TimePenalty is added to the game time when the cow is popped.
+	TimePenalty time.Duration
+
+	PopEffect      string
+	PopEffectScale float64
+	PopSound       string
+	WobbleSound    string
+}
+
+var CowArchetypes = map[string]*CowArchetype{
+	CowArchetypeRegular: {
+		Name:            CowArchetypeRegular,
+		Model:           "Cow",
+		Scale:           1.0,
+		CollisionRadius: 7.5,
+		PopImpactSpeed:  8.0,
+		Hits:            1,
+		Points:          1,
+		PopEffect:       "Burst",
+		PopEffectScale:  1.0,
+		PopSound:        "sound/pop.mp3",
+		WobbleSound:     "sound/rubbing.mp3",
+	},
+	CowArchetypeGolden: {
+		Name:            CowArchetypeGolden,
+		Model:           "Cow Golden",
+		Scale:           1.0,
+		CollisionRadius: 7.5,
+		PopImpactSpeed:  8.0,
+		Hits:            1,
+		Points:          3,
+		PopEffect:       "Burst Golden",
+		PopEffectScale:  1.5,
+		PopSound:        "sound/pop.mp3",
+		WobbleSound:     "sound/rubbing.mp3",
+	},
+	CowArchetypeArmored: {
+		Name:            CowArchetypeArmored,
+		Model:           "Cow Armored",
+		Scale:           1.2,
+		CollisionRadius: 9.0,
+		PopImpactSpeed:  20.0,
+		Hits:            3,
+		MinImpactSpeed:  40.0,
+		Points:          2,
+		PopEffect:       "Burst Armored",
+		PopEffectScale:  1.2,
+		PopSound:        "sound/pop.mp3",
+		WobbleSound:     "sound/rubbing.mp3",
+	},
+	CowArchetypeDecoy: {
+		Name:            CowArchetypeDecoy,
+		Model:           "Cow Decoy",
+		Scale:           1.0,
+		CollisionRadius: 7.5,
+		PopImpactSpeed:  5.0,
+		Hits:            1,
+		Points:          0,
+		TimePenalty:     15 * time.Second,
+		PopEffect:       "Burst Decoy",
+		PopEffectScale:  1.0,
+		PopSound:        "sound/rubbing.mp3",
+		WobbleSound:     "sound/rubbing.mp3",
+	},
+	CowArchetypeGiant: {
+		Name:            CowArchetypeGiant,
+		Model:           "Cow Giant",
+		Scale:           2.0,
+		CollisionRadius: 15.0,
+		PopImpactSpeed:  12.0,
+		Hits:            1,
+		Points:          2,
+		PopEffect:       "Burst Giant",
+		PopEffectScale:  2.0,
+		PopSound:        "sound/pop.mp3",
+		WobbleSound:     "sound/rubbing.mp3",
+	},
+}
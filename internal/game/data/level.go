@@ -0,0 +1,176 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mokiat/gomath/dprec"
+)
+
+const LevelWorld = "world"
+
+// Levels holds all playable levels, keyed by name.
+var Levels = map[string]*Level{
+	LevelWorld: worldLevel,
+}
+
+// Level holds gameplay configuration that is not part of the scene asset.
+type Level struct {
+	Name string
+
+	// Scene is the name of the scene asset that holds the level geometry.
+	// See SceneName for the asset that is actually loaded.
+	Scene string
+
+	TimeOfDay TimeOfDay
+
+	DefaultCowArchetype string
+
+	// CowArchetypes maps cow node names to the name of an archetype that
+	// overrides the default one.
+	CowArchetypes map[string]string
+
+	// SpawnZones lists areas where cows are placed procedurally, in
+	// addition to the ones placed by hand in the scene.
+	SpawnZones []SpawnZone
+
+	Wind Wind
+
+	Bounds Bounds
+}
+
+// minSpawnZoneArea is the smallest polygon area, in square meters, that a
+// spawn zone can have. Smaller polygons are almost never hit when sampled.
+const minSpawnZoneArea = 1.0
+
+var ErrInvalidLevel = errors.New("invalid level")
+
+// Validate checks that the level only refers to known cow archetypes, so
+// that the cows can be spawned once the level is loaded.
+func (l *Level) Validate() error {
+	if _, ok := CowArchetypes[l.DefaultCowArchetype]; !ok {
+		return fmt.Errorf("%w: %q has unknown default cow archetype %q", ErrInvalidLevel, l.Name, l.DefaultCowArchetype)
+	}
+	for node, name := range l.CowArchetypes {
+		if _, ok := CowArchetypes[name]; !ok {
+			return fmt.Errorf("%w: %q has unknown cow archetype %q for %q", ErrInvalidLevel, l.Name, name, node)
+		}
+	}
+	for i := range l.SpawnZones {
+		if err := l.validateSpawnZone(&l.SpawnZones[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Level) validateSpawnZone(zone *SpawnZone) error {
+	if zone.Archetype != "" {
+		if _, ok := CowArchetypes[zone.Archetype]; !ok {
+			return fmt.Errorf("%w: %q has unknown cow archetype %q for spawn zone %q", ErrInvalidLevel, l.Name, zone.Archetype, zone.Name)
+		}
+	}
+	switch {
+	case zone.UsesPolygon():
+		if zone.PolygonArea() < minSpawnZoneArea {
+			return fmt.Errorf("%w: %q has spawn zone %q with an empty polygon", ErrInvalidLevel, l.Name, zone.Name)
+		}
+	case len(zone.Polygon) > 0:
+		return fmt.Errorf("%w: %q has spawn zone %q with fewer than three polygon corners", ErrInvalidLevel, l.Name, zone.Name)
+	case zone.Node == "" || zone.Radius <= 0.0:
+		return fmt.Errorf("%w: %q has spawn zone %q without a polygon or a radius around a node", ErrInvalidLevel, l.Name, zone.Name)
+	}
+	return nil
+}
+
+// Lighting returns the lighting preset of the level's time of day.
+func (l *Level) Lighting() Lighting {
+	if lighting, ok := LightingPresets[l.TimeOfDay]; ok {
+		return lighting
+	}
+	return LightingPresets[TimeOfDayNoon]
+}
+
+// SceneName returns the name of the scene asset that combines the level
+// geometry with the sky of the level's time of day.
+func (l *Level) SceneName() string {
+	return l.Scene + l.Lighting().SceneSuffix
+}
+
+func (l *Level) CowArchetype(nodeName string) *CowArchetype {
+	if name, ok := l.CowArchetypes[nodeName]; ok {
+		return CowArchetypes[name]
+	}
+	return CowArchetypes[l.DefaultCowArchetype]
+}
+
+// SpawnZoneArchetype returns the archetype of the cows in the specified
+// zone.
+func (l *Level) SpawnZoneArchetype(zone *SpawnZone) *CowArchetype {
+	if archetype, ok := CowArchetypes[zone.Archetype]; ok {
+		return archetype
+	}
+	return CowArchetypes[l.DefaultCowArchetype]
+}
+
+var worldLevel = &Level{
+	Name:                LevelWorld,
+	Scene:               "World",
+	TimeOfDay:           TimeOfDayNoon,
+	DefaultCowArchetype: CowArchetypeRegular,
+	CowArchetypes: map[string]string{
+		"Cow.002": CowArchetypeGolden,
+		"Cow.007": CowArchetypeArmored,
+		"Cow.008": CowArchetypeDecoy,
+		"Cow.009": CowArchetypeDecoy,
+		"Cow.010": CowArchetypeGiant,
+	},
+	SpawnZones: []SpawnZone{
+		{
+			Name:       "Pasture",
+			Node:       "Cow.005",
+			Radius:     60.0,
+			Count:      4,
+			MinSpacing: 8.0,
+		},
+		{
+			Name: "Meadow",
+			Polygon: []dprec.Vec2{
+				dprec.NewVec2(-260.0, 40.0),
+				dprec.NewVec2(-140.0, 20.0),
+				dprec.NewVec2(-120.0, 140.0),
+				dprec.NewVec2(-240.0, 160.0),
+			},
+			Count:      5,
+			MinSpacing: 10.0,
+		},
+	},
+	Wind: Wind{
+		Velocity:     dprec.NewVec3(3.0, 0.0, 1.5),
+		GustStrength: 6.0,
+		GustInterval: 4 * time.Second,
+		TurbulenceZones: []TurbulenceZone{
+			{
+				Center:   dprec.NewVec3(150.0, 0.0, 200.0),
+				Radius:   120.0,
+				Height:   120.0,
+				Strength: 8.0,
+			},
+			{
+				Center:   dprec.NewVec3(-200.0, 0.0, -100.0),
+				Radius:   100.0,
+				Height:   100.0,
+				Strength: 6.0,
+			},
+		},
+	},
+	Bounds: Bounds{
+		Shape:   BoundsShapeCylinder,
+		Center:  dprec.ZeroVec3(),
+		Radius:  600.0,
+		Ceiling: 300.0,
+		Grace:   10 * time.Second,
+		Action:  OutOfBoundsRespawn,
+	},
+}
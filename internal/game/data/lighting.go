@@ -0,0 +1,89 @@
+package data
+
+import (
+	"github.com/mokiat/gomath/dprec"
+)
+
+const (
+	TimeOfDayDawn  TimeOfDay = "dawn"
+	TimeOfDayNoon  TimeOfDay = "noon"
+	TimeOfDayDusk  TimeOfDay = "dusk"
+	TimeOfDayNight TimeOfDay = "night"
+)
+
+type TimeOfDay string
+
+// Lighting describes how a level is lit at a particular time of day. The
+// sky and ambient lighting come from a scene asset that is packed for each
+// time of day.
+type Lighting struct {
+	TimeOfDay TimeOfDay
+
+	// SceneSuffix is appended to the scene name of a level to get the
+	// scene asset that uses the sky of this time of day.
+	SceneSuffix string
+
+	// Sun overrides the sun light that is authored in the scene. If nil,
+	// the sun is left as is.
+	Sun *Sun
+
+	Exposure float32
+}
+
+type Sun struct {
+	// Pitch is how high the sun is above the horizon.
+	Pitch dprec.Angle
+
+	// Yaw is the compass direction that the sun shines from.
+	Yaw dprec.Angle
+
+	// Tint is multiplied with the color of the sun light that is authored
+	// in the scene and accounts for both color and intensity.
+	Tint dprec.Vec3
+}
+
+// Rotation returns the rotation of the sun light.
+func (s *Sun) Rotation() dprec.Quat {
+	return dprec.QuatProd(
+		dprec.RotationQuat(s.Yaw, dprec.BasisYVec3()),
+		dprec.RotationQuat(-s.Pitch, dprec.BasisXVec3()),
+	)
+}
+
+var LightingPresets = map[TimeOfDay]Lighting{
+	TimeOfDayDawn: {
+		TimeOfDay:   TimeOfDayDawn,
+		SceneSuffix: " Dawn",
+		Sun: &Sun{
+			Pitch: dprec.Degrees(10),
+			Yaw:   dprec.Degrees(90),
+			Tint:  dprec.NewVec3(0.8, 0.55, 0.4),
+		},
+		Exposure: 2.5,
+	},
+	TimeOfDayNoon: {
+		TimeOfDay:   TimeOfDayNoon,
+		SceneSuffix: "",
+		Exposure:    2.0,
+	},
+	TimeOfDayDusk: {
+		TimeOfDay:   TimeOfDayDusk,
+		SceneSuffix: " Dusk",
+		Sun: &Sun{
+			Pitch: dprec.Degrees(8),
+			Yaw:   dprec.Degrees(-90),
+			Tint:  dprec.NewVec3(0.7, 0.4, 0.25),
+		},
+		Exposure: 2.8,
+	},
+	TimeOfDayNight: {
+		TimeOfDay:   TimeOfDayNight,
+		SceneSuffix: " Night",
+		Sun: &Sun{
+			Pitch: dprec.Degrees(40),
+			Yaw:   dprec.Degrees(-30),
+			Tint:  dprec.NewVec3(0.08, 0.1, 0.16),
+		},
+		Exposure: 6.0,
+	},
+}
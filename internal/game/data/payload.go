@@ -0,0 +1,19 @@
+package data
+
+// Payload describes the ball that hangs below the airplane.
+type Payload struct {
+	Name string
+	Mass float64
+}
+
+var (
+	PayloadLight  = Payload{Name: "light", Mass: 6.0}
+	PayloadNormal = Payload{Name: "normal", Mass: 10.0}
+	PayloadHeavy  = Payload{Name: "heavy", Mass: 16.0}
+)
+
+var Payloads = []Payload{
+	PayloadLight,
+	PayloadNormal,
+	PayloadHeavy,
+}
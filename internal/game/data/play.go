@@ -15,35 +15,67 @@ import (
 
 var random = rand.New(rand.NewSource(time.Now().UnixNano()))
 
-func LoadPlayData(audioAPI audio.API, engine *game.Engine, resourceSet *game.ResourceSet) async.Promise[*PlayData] {
-	scenePromise := resourceSet.OpenSceneByName("World")
-	airplanePromise := resourceSet.OpenModelByName("Airplane")
-	ballPromise := resourceSet.OpenModelByName("Ball")
-	cowPromise := resourceSet.OpenModelByName("Cow")
-	burstPromise := resourceSet.OpenModelByName("Burst")
-	soundtrackPromise := loadSound(audioAPI, engine, "sound/soundtrack.mp3")
-	popPromise := loadSound(audioAPI, engine, "sound/pop.mp3")
-	rubbingPromise := loadSound(audioAPI, engine, "sound/rubbing.mp3")
-	introPromise := loadSound(audioAPI, engine, fmt.Sprintf("sound/intro-%02d.mp3", 1+random.Intn(5)))
-	pilotPromise := loadSound(audioAPI, engine, fmt.Sprintf("sound/pilot-%02d.mp3", 1+random.Intn(5)))
-	towerPromise := loadSound(audioAPI, engine, fmt.Sprintf("sound/tower-%02d.mp3", 1+random.Intn(4)))
+func (c *PlayCache) load(entry *playCacheEntry, progress *LoadProgress) async.Promise[*PlayData] {
+	level := entry.level
+	resourceSet := entry.resourceSet
+	scenePromise := track(progress, level.SceneName(), resourceSet.OpenSceneByName(level.SceneName()))
+	airplanePromise := track(progress, "Airplane", resourceSet.OpenModelByName("Airplane"))
+	ballPromise := track(progress, "Ball", resourceSet.OpenModelByName("Ball"))
+	cowModelPromises := make(map[string]async.Promise[*game.ModelDefinition])
+	cowSoundPromises := make(map[string]async.Promise[audio.Media])
+	for _, archetype := range CowArchetypes {
+		for _, name := range []string{archetype.Model, archetype.PopEffect} {
+			if _, ok := cowModelPromises[name]; !ok {
+				cowModelPromises[name] = track(progress, name, resourceSet.OpenModelByName(name))
+			}
+		}
+		for _, name := range []string{archetype.PopSound, archetype.WobbleSound} {
+			if _, ok := cowSoundPromises[name]; !ok {
+				cowSoundPromises[name] = c.openSound(entry, progress, name)
+			}
+		}
+	}
+	soundtrackPromise := c.openSound(entry, progress, "sound/soundtrack.mp3")
+	rubbingPromise := c.openSound(entry, progress, "sound/rubbing.mp3")
+	introPromise := c.openSound(entry, progress, fmt.Sprintf("sound/intro-%02d.mp3", 1+random.Intn(5)))
+	pilotPromise := c.openSound(entry, progress, fmt.Sprintf("sound/pilot-%02d.mp3", 1+random.Intn(5)))
+	towerPromise := c.openSound(entry, progress, fmt.Sprintf("sound/tower-%02d.mp3", 1+random.Intn(4)))
+	towerCallbackPromise := c.openSound(entry, progress, "sound/tower-callback.mp3")
+	terrainPromise := async.NewDeliveredPromise[*Terrain](nil)
+	if len(level.SpawnZones) > 0 || entry.behaviours.Moving() {
+		terrainPromise = c.openTerrain(entry, progress)
+	}
 
 	result := async.NewPromise[*PlayData]()
 	go func() {
-		var data PlayData
+		data := PlayData{
+			Level:         level,
+			CowBehaviours: entry.behaviours,
+			CowModels:     make(map[string]*game.ModelDefinition),
+			CowSounds:     make(map[string]audio.Media),
+		}
 		err := errors.Join(
 			scenePromise.Inject(&data.Scene),
 			airplanePromise.Inject(&data.Airplane),
 			ballPromise.Inject(&data.Ball),
-			cowPromise.Inject(&data.Cow),
-			burstPromise.Inject(&data.Burst),
 			soundtrackPromise.Inject(&data.Soundtrack),
-			popPromise.Inject(&data.Pop),
 			rubbingPromise.Inject(&data.Rubbing),
 			introPromise.Inject(&data.IntroSound),
 			pilotPromise.Inject(&data.PilotSound),
 			towerPromise.Inject(&data.TowerSound),
+			towerCallbackPromise.Inject(&data.TowerCallback),
+			terrainPromise.Inject(&data.Terrain),
 		)
+		for name, promise := range cowModelPromises {
+			model, modelErr := promise.Wait()
+			err = errors.Join(err, modelErr)
+			data.CowModels[name] = model
+		}
+		for name, promise := range cowSoundPromises {
+			sound, soundErr := promise.Wait()
+			err = errors.Join(err, soundErr)
+			data.CowSounds[name] = sound
+		}
 		if err != nil {
 			result.Fail(err)
 		} else {
@@ -54,17 +86,32 @@ func LoadPlayData(audioAPI audio.API, engine *game.Engine, resourceSet *game.Res
 }
 
 type PlayData struct {
-	Scene      *game.SceneDefinition
-	Airplane   *game.ModelDefinition
-	Ball       *game.ModelDefinition
-	Cow        *game.ModelDefinition
-	Burst      *game.ModelDefinition
-	Soundtrack audio.Media
-	Pop        audio.Media
-	Rubbing    audio.Media
-	IntroSound audio.Media
-	PilotSound audio.Media
-	TowerSound audio.Media
+	Level         *Level
+	CowBehaviours *CowBehaviours
+	Scene         *game.SceneDefinition
+	Airplane      *game.ModelDefinition
+	Ball          *game.ModelDefinition
+	Soundtrack    audio.Media
+	Rubbing       audio.Media
+	IntroSound    audio.Media
+	PilotSound    audio.Media
+	TowerSound    audio.Media
+
+	// TowerCallback is played when the tower calls back a player that has
+	// left the level bounds.
+	TowerCallback audio.Media
+
+	// Terrain is only loaded when the level has spawn zones or cows that
+	// walk around.
+	Terrain *Terrain
+
+	// CowModels holds the cow and pop effect models of all archetypes,
+	// keyed by resource name.
+	CowModels map[string]*game.ModelDefinition
+
+	// CowSounds holds the pop and wobble sounds of all archetypes, keyed
+	// by path.
+	CowSounds map[string]audio.Media
 }
 
 func loadSound(audioAPI audio.API, engine *game.Engine, name string) async.Promise[audio.Media] {
@@ -0,0 +1,63 @@
+package data
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/mokiat/lacking/util/async"
+)
+
+// AssetError indicates that a particular asset could not be loaded.
+type AssetError struct {
+	Asset string
+	Err   error
+}
+
+func (e *AssetError) Error() string {
+	return fmt.Sprintf("failed to load %q: %v", e.Asset, e.Err)
+}
+
+func (e *AssetError) Unwrap() error {
+	return e.Err
+}
+
+func NewLoadProgress() *LoadProgress {
+	return &LoadProgress{}
+}
+
+// LoadProgress keeps track of how many of the scheduled assets have
+// finished loading. It is safe for concurrent use.
+type LoadProgress struct {
+	total atomic.Int32
+	done  atomic.Int32
+}
+
+// Ratio returns the fraction of the scheduled assets that have finished
+// loading, in the range [0.0, 1.0].
+func (p *LoadProgress) Ratio() float64 {
+	total := p.total.Load()
+	if total == 0 {
+		return 0.0
+	}
+	return float64(p.done.Load()) / float64(total)
+}
+
+// track registers the promise with the progress and wraps any failure into
+// an AssetError with the specified asset name.
+func track[T any](progress *LoadProgress, asset string, promise async.Promise[T]) async.Promise[T] {
+	progress.total.Add(1)
+	result := async.NewPromise[T]()
+	go func() {
+		value, err := promise.Wait()
+		progress.done.Add(1)
+		if err != nil {
+			result.Fail(&AssetError{
+				Asset: asset,
+				Err:   err,
+			})
+		} else {
+			result.Deliver(value)
+		}
+	}()
+	return result
+}
@@ -0,0 +1,42 @@
+package data
+
+import "time"
+
+const dailyRecordsFile = "daily.json"
+
+// DailyRecord holds the results of a player for one daily challenge.
+type DailyRecord struct {
+	Date string        `json:"date"`
+	Best time.Duration `json:"best"`
+	Wins int           `json:"wins"`
+}
+
+// LoadDailyRecords reads the locally stored daily challenge results, keyed
+// by date. A missing file is not an error.
+func LoadDailyRecords() (map[string]DailyRecord, error) {
+	records := make(map[string]DailyRecord)
+	if err := loadJSON(dailyRecordsFile, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// RecordDailyResult stores the specified completion time of a daily
+// challenge and returns the updated record for that day.
+func RecordDailyResult(date string, result time.Duration) (DailyRecord, error) {
+	records, err := LoadDailyRecords()
+	if err != nil {
+		return DailyRecord{}, err
+	}
+	record, ok := records[date]
+	if !ok || result < record.Best {
+		record.Best = result
+	}
+	record.Date = date
+	record.Wins++
+	records[date] = record
+	if err := saveJSON(dailyRecordsFile, records); err != nil {
+		return DailyRecord{}, err
+	}
+	return record, nil
+}
@@ -0,0 +1,57 @@
+package data
+
+const settingsFile = "settings.json"
+
+const (
+	MinMouseSensitivity     = 0.25
+	MaxMouseSensitivity     = 4.0
+	DefaultMouseSensitivity = 1.0
+)
+
+// ControlSettings holds the preferences of the player for flying the
+// airplane.
+type ControlSettings struct {
+	// MouseFlight makes the keyboard player steer with the mouse, which
+	// points to where the airplane should fly.
+	MouseFlight bool `json:"mouse_flight"`
+
+	// MouseSensitivity scales how sharply the airplane turns for a given
+	// cursor offset from the center of the view.
+	MouseSensitivity float64 `json:"mouse_sensitivity"`
+
+	// InvertMouse flips the vertical mouse axis.
+	InvertMouse bool `json:"invert_mouse"`
+}
+
+// Settings holds the preferences of the player.
+type Settings struct {
+	Controls ControlSettings `json:"controls"`
+}
+
+// DefaultSettings returns the settings that are used until the player
+// changes them.
+func DefaultSettings() Settings {
+	return Settings{
+		Controls: ControlSettings{
+			MouseSensitivity: DefaultMouseSensitivity,
+		},
+	}
+}
+
+// LoadSettings reads the locally stored settings. A missing file is not an
+// error and yields the defaults.
+func LoadSettings() (Settings, error) {
+	settings := DefaultSettings()
+	if err := loadJSON(settingsFile, &settings); err != nil {
+		return DefaultSettings(), err
+	}
+	if settings.Controls.MouseSensitivity < MinMouseSensitivity || settings.Controls.MouseSensitivity > MaxMouseSensitivity {
+		settings.Controls.MouseSensitivity = DefaultMouseSensitivity
+	}
+	return settings, nil
+}
+
+// SaveSettings stores the specified settings locally.
+func SaveSettings(settings Settings) error {
+	return saveJSON(settingsFile, settings)
+}
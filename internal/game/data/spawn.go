@@ -0,0 +1,79 @@
+package data
+
+import (
+	"github.com/mokiat/gomath/dprec"
+)
+
+// SpawnZone describes an area of a level where cows are placed
+// procedurally on the terrain surface.
+//
+// The area is either a polygon or a circle around a scene node. When both
+// are specified, the polygon is used.
+type SpawnZone struct {
+	Name string
+
+	// Polygon lists the corners of the area in the XZ plane.
+	Polygon []dprec.Vec2
+
+	// Node is the name of the scene node around which the area is centred.
+	Node string
+
+	// Radius is the radius of the area around Node.
+	Radius float64
+
+	// Count is the number of cows to place in the zone.
+	Count int
+
+	// MinSpacing is the smallest allowed distance between any two cows.
+	MinSpacing float64
+
+	// Archetype specifies the cows in the zone. An empty value falls back
+	// to the level default. How the cows behave is part of the level's
+	// CowBehaviours.
+	Archetype string
+}
+
+// UsesPolygon returns whether the zone area is specified as a polygon.
+func (z *SpawnZone) UsesPolygon() bool {
+	return len(z.Polygon) >= 3
+}
+
+// PolygonArea returns the area that the polygon of the zone covers in
+// the XZ plane.
+func (z *SpawnZone) PolygonArea() float64 {
+	var area float64
+	for i, j := 0, len(z.Polygon)-1; i < len(z.Polygon); j, i = i, i+1 {
+		a, b := z.Polygon[i], z.Polygon[j]
+		area += (b.X + a.X) * (b.Y - a.Y)
+	}
+	return dprec.Abs(area) / 2.0
+}
+
+// PolygonBounds returns the smallest rectangle in the XZ plane that
+// contains the polygon of the zone.
+func (z *SpawnZone) PolygonBounds() (dprec.Vec2, dprec.Vec2) {
+	minimum := z.Polygon[0]
+	maximum := z.Polygon[0]
+	for _, point := range z.Polygon[1:] {
+		minimum = dprec.NewVec2(min(minimum.X, point.X), min(minimum.Y, point.Y))
+		maximum = dprec.NewVec2(max(maximum.X, point.X), max(maximum.Y, point.Y))
+	}
+	return minimum, maximum
+}
+
+// PolygonContains returns whether the specified point in the XZ plane is
+// inside the polygon of the zone.
+func (z *SpawnZone) PolygonContains(point dprec.Vec2) bool {
+	inside := false
+	for i, j := 0, len(z.Polygon)-1; i < len(z.Polygon); j, i = i, i+1 {
+		a, b := z.Polygon[i], z.Polygon[j]
+		if (a.Y > point.Y) == (b.Y > point.Y) {
+			continue
+		}
+		crossing := a.X + (point.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y)
+		if point.X < crossing {
+			inside = !inside
+		}
+	}
+	return inside
+}
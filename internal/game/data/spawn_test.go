@@ -0,0 +1,73 @@
+package data
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mokiat/gomath/dprec"
+)
+
+func TestSpawnZonePolygonArea(t *testing.T) {
+	zone := SpawnZone{
+		Polygon: []dprec.Vec2{
+			dprec.NewVec2(0.0, 0.0),
+			dprec.NewVec2(10.0, 0.0),
+			dprec.NewVec2(10.0, 5.0),
+			dprec.NewVec2(0.0, 5.0),
+		},
+	}
+	if area := zone.PolygonArea(); area != 50.0 {
+		t.Errorf("expected area 50, got %f", area)
+	}
+	if !zone.PolygonContains(dprec.NewVec2(5.0, 2.5)) {
+		t.Errorf("expected polygon to contain its centre")
+	}
+	if zone.PolygonContains(dprec.NewVec2(15.0, 2.5)) {
+		t.Errorf("expected polygon not to contain an outside point")
+	}
+}
+
+func TestWorldLevelSpawnZones(t *testing.T) {
+	if len(worldLevel.SpawnZones) == 0 {
+		t.Fatalf("expected world level to have spawn zones")
+	}
+	if err := worldLevel.Validate(); err != nil {
+		t.Fatalf("expected world level to be valid: %v", err)
+	}
+}
+
+func TestLevelValidateSpawnZones(t *testing.T) {
+	testCases := map[string]SpawnZone{
+		"unknown archetype": {
+			Node:      "Cow.001",
+			Radius:    10.0,
+			Archetype: "unknown",
+		},
+		"collinear polygon": {
+			Polygon: []dprec.Vec2{
+				dprec.NewVec2(0.0, 0.0),
+				dprec.NewVec2(5.0, 5.0),
+				dprec.NewVec2(10.0, 10.0),
+			},
+		},
+		"too few corners": {
+			Polygon: []dprec.Vec2{
+				dprec.NewVec2(0.0, 0.0),
+				dprec.NewVec2(5.0, 5.0),
+			},
+		},
+		"missing radius": {
+			Node: "Cow.001",
+		},
+	}
+	for name, zone := range testCases {
+		level := &Level{
+			Name:                "test",
+			DefaultCowArchetype: CowArchetypeRegular,
+			SpawnZones:          []SpawnZone{zone},
+		}
+		if err := level.Validate(); !errors.Is(err, ErrInvalidLevel) {
+			t.Errorf("%s: expected invalid level error, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStorageRoundTrip(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	records, err := LoadDailyRecords()
+	if err != nil {
+		t.Fatalf("failed to load missing records: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("expected no records, got %d", len(records))
+	}
+
+	if _, err := RecordDailyResult("2024-01-26", 90*time.Second); err != nil {
+		t.Fatalf("failed to record result: %v", err)
+	}
+	record, err := RecordDailyResult("2024-01-26", 60*time.Second)
+	if err != nil {
+		t.Fatalf("failed to record result: %v", err)
+	}
+	if record.Best != 60*time.Second || record.Wins != 2 {
+		t.Errorf("expected best 1m0s after 2 wins, got %v after %d", record.Best, record.Wins)
+	}
+
+	records, err = LoadDailyRecords()
+	if err != nil {
+		t.Fatalf("failed to load records: %v", err)
+	}
+	if records["2024-01-26"] != record {
+		t.Errorf("expected stored record %+v, got %+v", record, records["2024-01-26"])
+	}
+
+	settings, err := LoadSettings()
+	if err != nil {
+		t.Fatalf("failed to load missing settings: %v", err)
+	}
+	if settings != DefaultSettings() {
+		t.Errorf("expected default settings, got %+v", settings)
+	}
+	settings.Controls.MouseSensitivity = 100.0
+	if err := SaveSettings(settings); err != nil {
+		t.Fatalf("failed to save settings: %v", err)
+	}
+	settings, err = LoadSettings()
+	if err != nil {
+		t.Fatalf("failed to load settings: %v", err)
+	}
+	if settings.Controls.MouseSensitivity != DefaultMouseSensitivity {
+		t.Errorf("expected out of range sensitivity to be reset, got %v", settings.Controls.MouseSensitivity)
+	}
+}
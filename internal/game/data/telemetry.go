@@ -0,0 +1,172 @@
+package data
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/mokiat/gomath/dprec"
+)
+
+const (
+	TelemetryFormatCSV       TelemetryFormat = "csv"
+	TelemetryFormatJSONLines TelemetryFormat = "jsonl"
+)
+
+// TelemetryFormat identifies how telemetry samples are written.
+type TelemetryFormat string
+
+// TelemetrySample is the state of one airplane after one simulation step.
+// Angles are in degrees.
+type TelemetrySample struct {
+	Step            int        `json:"step"`
+	Time            float64    `json:"time"`
+	Player          int        `json:"player"`
+	Position        dprec.Vec3 `json:"position"`
+	Velocity        dprec.Vec3 `json:"velocity"`
+	Rotation        dprec.Quat `json:"rotation"`
+	AngularVelocity dprec.Vec3 `json:"angular_velocity"`
+	Aileron         float64    `json:"aileron"`
+	Elevator        float64    `json:"elevator"`
+	Rudder          float64    `json:"rudder"`
+	Thrust          float64    `json:"thrust"`
+	TargetThrust    float64    `json:"target_thrust"`
+	BallPosition    dprec.Vec3 `json:"ball_position"`
+}
+
+// TelemetryWriter stores telemetry samples.
+type TelemetryWriter interface {
+	Write(sample TelemetrySample) error
+
+	// Close flushes any buffered samples and closes the destination, if
+	// it can be closed.
+	Close() error
+}
+
+// NewTelemetryWriter returns a TelemetryWriter that writes samples in the
+// specified format to out.
+func NewTelemetryWriter(out io.Writer, format TelemetryFormat) (TelemetryWriter, error) {
+	buffered := bufio.NewWriter(out)
+	switch format {
+	case TelemetryFormatCSV:
+		return &csvTelemetryWriter{
+			out:      out,
+			buffered: buffered,
+			csv:      csv.NewWriter(buffered),
+		}, nil
+	case TelemetryFormatJSONLines:
+		return &jsonTelemetryWriter{
+			out:      out,
+			buffered: buffered,
+			encoder:  json.NewEncoder(buffered),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown telemetry format %q", format)
+	}
+}
+
+// CreateTelemetryFile creates a new file for telemetry in the specified
+// format, named after the current time.
+func CreateTelemetryFile(format TelemetryFormat) (*os.File, error) {
+	dir, err := configPath("telemetry")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create telemetry directory: %w", err)
+	}
+	name := fmt.Sprintf("telemetry-%s.%s", time.Now().Format("20060102-150405"), format)
+	file, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create telemetry file: %w", err)
+	}
+	return file, nil
+}
+
+var telemetryCSVHeader = []string{
+	"step", "time", "player",
+	"position_x", "position_y", "position_z",
+	"velocity_x", "velocity_y", "velocity_z",
+	"rotation_w", "rotation_x", "rotation_y", "rotation_z",
+	"angular_velocity_x", "angular_velocity_y", "angular_velocity_z",
+	"aileron", "elevator", "rudder", "thrust", "target_thrust",
+	"ball_position_x", "ball_position_y", "ball_position_z",
+}
+
+type csvTelemetryWriter struct {
+	out         io.Writer
+	buffered    *bufio.Writer
+	csv         *csv.Writer
+	wroteHeader bool
+}
+
+func (w *csvTelemetryWriter) Write(sample TelemetrySample) error {
+	if !w.wroteHeader {
+		if err := w.csv.Write(telemetryCSVHeader); err != nil {
+			return err
+		}
+		w.wroteHeader = true
+	}
+	record := []string{
+		strconv.Itoa(sample.Step), formatFloat(sample.Time), strconv.Itoa(sample.Player),
+	}
+	record = appendVec3(record, sample.Position)
+	record = appendVec3(record, sample.Velocity)
+	record = append(record,
+		formatFloat(sample.Rotation.W), formatFloat(sample.Rotation.X),
+		formatFloat(sample.Rotation.Y), formatFloat(sample.Rotation.Z),
+	)
+	record = appendVec3(record, sample.AngularVelocity)
+	record = append(record,
+		formatFloat(sample.Aileron), formatFloat(sample.Elevator), formatFloat(sample.Rudder),
+		formatFloat(sample.Thrust), formatFloat(sample.TargetThrust),
+	)
+	record = appendVec3(record, sample.BallPosition)
+	return w.csv.Write(record)
+}
+
+func (w *csvTelemetryWriter) Close() error {
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+	return closeTelemetry(w.buffered, w.out)
+}
+
+type jsonTelemetryWriter struct {
+	out      io.Writer
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+}
+
+func (w *jsonTelemetryWriter) Write(sample TelemetrySample) error {
+	return w.encoder.Encode(sample)
+}
+
+func (w *jsonTelemetryWriter) Close() error {
+	return closeTelemetry(w.buffered, w.out)
+}
+
+func closeTelemetry(buffered *bufio.Writer, out io.Writer) error {
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	if closer, ok := out.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func appendVec3(record []string, v dprec.Vec3) []string {
+	return append(record, formatFloat(v.X), formatFloat(v.Y), formatFloat(v.Z))
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,124 @@
+package data
+
+import (
+	"fmt"
+
+	"github.com/mokiat/gomath/dprec"
+	"github.com/mokiat/lacking/game"
+	"github.com/mokiat/lacking/game/asset"
+	"github.com/mokiat/lacking/game/physics/collision"
+	"github.com/mokiat/lacking/util/async"
+)
+
+// Terrain holds the static collision geometry of a scene, so that it can
+// be queried outside of the physics simulation, which does not support
+// ray casts.
+type Terrain struct {
+	meshes []collision.Mesh
+}
+
+// RayCast returns the closest point to from where the segment between from
+// and to hits the terrain.
+func (t *Terrain) RayCast(from, to dprec.Vec3) (dprec.Vec3, bool) {
+	line := collision.NewLine(from, to)
+	lineCenter := dprec.Vec3Lerp(from, to, 0.5)
+	lineRadius := line.Length() / 2.0
+
+	var (
+		result    dprec.Vec3
+		distance  float64
+		found     bool
+		collector collision.LastIntersection
+	)
+	for _, mesh := range t.meshes {
+		sphere := mesh.BoundingSphere()
+		if dprec.Vec3Diff(sphere.Position(), lineCenter).Length() > sphere.Radius()+lineRadius {
+			continue
+		}
+		for _, triangle := range mesh.Triangles() {
+			collector.Reset()
+			collision.CheckIntersectionLineWithTriangle(line, triangle, false, &collector)
+			intersection, ok := collector.Intersection()
+			if !ok {
+				continue
+			}
+			contactDistance := dprec.Vec3Diff(intersection.FirstContact, from).Length()
+			if !found || contactDistance < distance {
+				result = intersection.FirstContact
+				distance = contactDistance
+				found = true
+			}
+		}
+	}
+	return result, found
+}
+
+func loadTerrain(engine *game.Engine, sceneName string) async.Promise[*Terrain] {
+	result := async.NewPromise[*Terrain]()
+	go func() {
+		resource := engine.Registry().ResourceByName(sceneName)
+		if resource == nil {
+			result.Fail(fmt.Errorf("scene %q not found", sceneName))
+			return
+		}
+		sceneAsset := new(asset.Scene)
+		ioTask := func() error {
+			return resource.ReadContent(sceneAsset)
+		}
+		if err := engine.IOWorker().Schedule(ioTask).Wait(); err != nil {
+			result.Fail(fmt.Errorf("failed to read scene: %w", err))
+			return
+		}
+
+		terrain := &Terrain{}
+		terrain.appendModel(&sceneAsset.Model, dprec.IdentityMat4())
+		for _, instance := range sceneAsset.ModelInstances {
+			if instance.ModelIndex < 0 || int(instance.ModelIndex) >= len(sceneAsset.ModelDefinitions) {
+				continue
+			}
+			matrix := dprec.TRSMat4(instance.Translation, instance.Rotation, instance.Scale)
+			terrain.appendModel(&sceneAsset.ModelDefinitions[instance.ModelIndex], matrix)
+		}
+		result.Deliver(terrain)
+	}()
+	return result
+}
+
+func (t *Terrain) appendModel(model *asset.Model, modelMatrix dprec.Mat4) {
+	nodeMatrices := make([]dprec.Mat4, len(model.Nodes))
+	var nodeMatrix func(index int32) dprec.Mat4
+	nodeMatrix = func(index int32) dprec.Mat4 {
+		if index < 0 {
+			return modelMatrix
+		}
+		if nodeMatrices[index] == (dprec.Mat4{}) {
+			node := model.Nodes[index]
+			nodeMatrices[index] = dprec.Mat4Prod(
+				nodeMatrix(node.ParentIndex),
+				dprec.TRSMat4(node.Translation, node.Rotation, node.Scale),
+			)
+		}
+		return nodeMatrices[index]
+	}
+
+	for _, instance := range model.BodyInstances {
+		matrix := nodeMatrix(instance.NodeIndex)
+		definition := model.BodyDefinitions[instance.BodyIndex]
+		for _, meshAsset := range definition.CollisionMeshes {
+			meshMatrix := dprec.Mat4Prod(matrix, dprec.TRSMat4(
+				meshAsset.Translation,
+				meshAsset.Rotation,
+				dprec.NewVec3(1.0, 1.0, 1.0),
+			))
+			triangles := make([]collision.Triangle, len(meshAsset.Triangles))
+			for i, triangleAsset := range meshAsset.Triangles {
+				triangles[i] = collision.NewTriangle(
+					dprec.Mat4Vec3Transformation(meshMatrix, triangleAsset.A),
+					dprec.Mat4Vec3Transformation(meshMatrix, triangleAsset.B),
+					dprec.Mat4Vec3Transformation(meshMatrix, triangleAsset.C),
+				)
+			}
+			t.meshes = append(t.meshes, collision.NewMesh(triangles))
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package data
+
+import (
+	"time"
+
+	"github.com/mokiat/gomath/dprec"
+)
+
+// Wind describes how the air moves over a level.
+type Wind struct {
+	// Velocity is the steady wind, in meters per second.
+	Velocity dprec.Vec3
+
+	// GustStrength is the largest speed that gusts add on top of the
+	// steady wind.
+	GustStrength float64
+
+	// GustInterval is roughly how often the gust strength changes.
+	GustInterval time.Duration
+
+	TurbulenceZones []TurbulenceZone
+}
+
+// TurbulenceZone is a region of chaotic air, for example over a hill.
+type TurbulenceZone struct {
+	Center dprec.Vec3
+
+	// Radius is the horizontal extent of the zone. The turbulence fades
+	// out towards the edge.
+	Radius float64
+
+	// Height is how far above the center the turbulence reaches.
+	Height float64
+
+	// Strength is the largest speed that the turbulence can reach.
+	Strength float64
+}
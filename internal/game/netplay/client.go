@@ -0,0 +1,295 @@
+package netplay
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mokiat/lacking/debug/log"
+)
+
+const (
+	helloInterval = 200 * time.Millisecond
+
+	// maxResentInputs limits how many unacknowledged inputs are repeated
+	// with every input packet, to cover for lost packets.
+	maxResentInputs = 8
+
+	maxBufferedSnapshots = 32
+
+	// The server is considered gone when it stops sending snapshots. The
+	// first snapshot is given more time, since the server may still be
+	// loading the level.
+	serverTimeout        = 5 * time.Second
+	firstSnapshotTimeout = 30 * time.Second
+)
+
+// ErrRejected indicates that the server did not accept the client.
+var ErrRejected = errors.New("rejected by server")
+
+// Dial connects to the server at the specified address, waiting up to
+// the specified timeout for it to accept the client.
+func Dial(address string, timeout time.Duration) (*Client, error) {
+	udpAddr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		return nil, err
+	}
+	hello, err := encodeHello()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	buffer := make([]byte, maxPacketSize)
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, err := conn.Write(hello); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn.SetReadDeadline(time.Now().Add(helloInterval))
+		n, err := conn.Read(buffer)
+		if err != nil {
+			// Nobody may be listening yet, so keep trying until the
+			// timeout runs out.
+			continue
+		}
+		r, kind, err := openPacket(buffer[:n])
+		if err != nil {
+			continue
+		}
+		switch kind {
+		case kindWelcome:
+			player, seed, err := decodeWelcome(r)
+			if err != nil {
+				continue
+			}
+			conn.SetReadDeadline(time.Time{})
+			client := &Client{
+				conn:   conn,
+				player: player,
+				seed:   seed,
+				start:  time.Now(),
+				done:   make(chan struct{}),
+			}
+			go client.receive()
+			return client, nil
+		case kindReject:
+			reason, _ := decodeReject(r)
+			conn.Close()
+			return nil, fmt.Errorf("%w: %s", ErrRejected, reason)
+		}
+	}
+	conn.Close()
+	return nil, fmt.Errorf("no response from %s", address)
+}
+
+// Client is the remote side of a network game. It sends the inputs of the
+// local player to the server and buffers the snapshots that it receives.
+type Client struct {
+	conn   *net.UDPConn
+	player uint8
+	seed   int64
+	start  time.Time
+
+	mu           sync.Mutex
+	sequence     uint32
+	pending      []Input
+	snapshots    []timedSnapshot
+	offset       time.Duration
+	disconnected bool
+
+	done chan struct{}
+}
+
+type timedSnapshot struct {
+	Snapshot
+	receivedAt time.Duration
+}
+
+// Player returns the player that the server assigned to this client.
+func (c *Client) Player() uint8 {
+	return c.player
+}
+
+// Seed returns the seed that the server shared with this client.
+func (c *Client) Seed() int64 {
+	return c.seed
+}
+
+// Disconnected returns whether the server has ended the game or has
+// stopped responding.
+func (c *Client) Disconnected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.disconnected
+}
+
+// SendInput assigns the next sequence to the input and sends it, together
+// with any inputs that the server has not acknowledged yet.
+func (c *Client) SendInput(input Input) (uint32, error) {
+	c.mu.Lock()
+	c.sequence++
+	input.Sequence = c.sequence
+	c.pending = append(c.pending, input)
+	resent := c.pending[max(0, len(c.pending)-maxResentInputs):]
+	packet, err := encodeInputs(resent)
+	c.mu.Unlock()
+	if err != nil {
+		return input.Sequence, err
+	}
+	_, err = c.conn.Write(packet)
+	return input.Sequence, err
+}
+
+// Latest returns the most recent snapshot that was received.
+func (c *Client) Latest() (Snapshot, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.snapshots) == 0 {
+		return Snapshot{}, false
+	}
+	return c.snapshots[len(c.snapshots)-1].Snapshot, true
+}
+
+// Interpolated returns the state of the world as the server saw it delay
+// ago, blended between the two snapshots around that moment. The delay
+// should span a few snapshots, so that a lost one does not cause a jump.
+func (c *Client) Interpolated(delay time.Duration) (Snapshot, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.snapshots) == 0 {
+		return Snapshot{}, false
+	}
+	serverTime := time.Since(c.start) - c.offset - delay
+	return interpolateBuffer(c.snapshots, serverTime), true
+}
+
+// Close tells the server that the client is leaving and releases the
+// socket.
+func (c *Client) Close() error {
+	if packet, err := encodeBye(); err == nil {
+		c.conn.Write(packet)
+	}
+	err := c.conn.Close()
+	<-c.done
+	return err
+}
+
+func (c *Client) receive() {
+	defer close(c.done)
+	buffer := make([]byte, maxPacketSize)
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(c.receiveTimeout()))
+		n, err := c.conn.Read(buffer)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				log.Warn("Server stopped responding")
+				c.disconnect()
+				return
+			}
+			// The server may not be listening for a moment, which some
+			// platforms report as a read error.
+			log.Warn("Network read error: %v", err)
+			continue
+		}
+		r, kind, err := openPacket(buffer[:n])
+		if err != nil {
+			continue
+		}
+		switch kind {
+		case kindSnapshot:
+			part, err := decodeSnapshot(r)
+			if err != nil {
+				continue
+			}
+			c.addSnapshot(part)
+			if part.Finished {
+				// The server repeats the final snapshot until it hears
+				// back, so a lost acknowledgement is covered too.
+				if packet, err := encodeFinishedAck(); err == nil {
+					c.conn.Write(packet)
+				}
+			}
+		case kindBye:
+			c.disconnect()
+		}
+	}
+}
+
+func (c *Client) receiveTimeout() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.snapshots) == 0 {
+		return firstSnapshotTimeout
+	}
+	return serverTimeout
+}
+
+func (c *Client) disconnect() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.disconnected = true
+}
+
+func (c *Client) addSnapshot(part snapshotPart) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := len(c.snapshots)
+	if count > 0 && part.Tick == c.snapshots[count-1].Tick {
+		// Another part of the latest snapshot, which only adds cows.
+		latest := &c.snapshots[count-1].Snapshot
+		if len(latest.Cows) == part.CowTotal {
+			copy(latest.Cows[part.CowOffset:], part.Cows)
+		}
+		return
+	}
+	if count > 0 && part.Tick < c.snapshots[count-1].Tick {
+		return // out of order
+	}
+
+	// Cows that are in parts that have not arrived (yet) keep their
+	// previous state.
+	snapshot := part.Snapshot
+	snapshot.Cows = make([]CowState, part.CowTotal)
+	if count > 0 {
+		copy(snapshot.Cows, c.snapshots[count-1].Cows)
+	}
+	copy(snapshot.Cows[part.CowOffset:], part.Cows)
+
+	// The offset between the local and the server clock is estimated from
+	// the fastest packet, since delays only ever make packets late. It is
+	// allowed to drift up slowly, in case the clocks run at different rates.
+	receivedAt := time.Since(c.start)
+	offset := receivedAt - snapshot.Time
+	if len(c.snapshots) == 0 || offset < c.offset {
+		c.offset = offset
+	} else {
+		c.offset += (offset - c.offset) / 100
+	}
+
+	c.snapshots = append(c.snapshots, timedSnapshot{
+		Snapshot:   snapshot,
+		receivedAt: receivedAt,
+	})
+	if len(c.snapshots) > maxBufferedSnapshots {
+		c.snapshots = c.snapshots[1:]
+	}
+
+	acked := 0
+	for acked < len(c.pending) && c.pending[acked].Sequence <= snapshot.Ack {
+		acked++
+	}
+	c.pending = c.pending[acked:]
+}
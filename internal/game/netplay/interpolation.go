@@ -0,0 +1,118 @@
+package netplay
+
+import (
+	"time"
+
+	"github.com/mokiat/gomath/dprec"
+)
+
+// Interpolate blends two snapshots, where t of 0.0 yields from and t of
+// 1.0 yields to. Players and cows that are missing from either snapshot,
+// as well as discrete state, are taken from to.
+func Interpolate(from, to Snapshot, t float64) Snapshot {
+	t = dprec.Clamp(t, 0.0, 1.0)
+	result := to
+	result.Time = from.Time + time.Duration(float64(to.Time-from.Time)*t)
+	result.Players = make([]PlayerState, len(to.Players))
+	for i, player := range to.Players {
+		if previous, ok := from.Player(player.Player); ok {
+			player.Airplane = InterpolateBody(previous.Airplane, player.Airplane, t)
+			player.Ball = InterpolateBody(previous.Ball, player.Ball, t)
+		}
+		result.Players[i] = player
+	}
+	result.Cows = make([]CowState, len(to.Cows))
+	for i, cow := range to.Cows {
+		if i < len(from.Cows) && from.Cows[i].Active && cow.Active {
+			cow.Position = dprec.Vec3Lerp(from.Cows[i].Position, cow.Position, t)
+		}
+		result.Cows[i] = cow
+	}
+	return result
+}
+
+// InterpolateBody blends two body states.
+func InterpolateBody(from, to BodyState, t float64) BodyState {
+	return BodyState{
+		Position:        dprec.Vec3Lerp(from.Position, to.Position, t),
+		Rotation:        dprec.QuatSlerp(from.Rotation, to.Rotation, t),
+		Velocity:        dprec.Vec3Lerp(from.Velocity, to.Velocity, t),
+		AngularVelocity: dprec.Vec3Lerp(from.AngularVelocity, to.AngularVelocity, t),
+	}
+}
+
+// interpolateBuffer returns the state at the specified server time from
+// snapshots that are sorted by time. Times outside the buffer are clamped
+// to the oldest or newest snapshot.
+func interpolateBuffer(snapshots []timedSnapshot, serverTime time.Duration) Snapshot {
+	if serverTime <= snapshots[0].Time {
+		return snapshots[0].Snapshot
+	}
+	for i := 1; i < len(snapshots); i++ {
+		from, to := snapshots[i-1], snapshots[i]
+		if serverTime > to.Time {
+			continue
+		}
+		span := to.Time - from.Time
+		if span <= 0 {
+			return to.Snapshot
+		}
+		return Interpolate(from.Snapshot, to.Snapshot, float64(serverTime-from.Time)/float64(span))
+	}
+	return snapshots[len(snapshots)-1].Snapshot
+}
+
+const maxPredictionHistory = 256
+
+// Predictor keeps the locally predicted states of a body, keyed by the
+// input that produced them, so that they can be compared with the
+// authoritative state once the server acknowledges the input.
+type Predictor struct {
+	history []predictedState
+}
+
+type predictedState struct {
+	sequence uint32
+	state    BodyState
+}
+
+// Record stores the predicted state after the specified input was applied.
+func (p *Predictor) Record(sequence uint32, state BodyState) {
+	p.history = append(p.history, predictedState{
+		sequence: sequence,
+		state:    state,
+	})
+	if len(p.history) > maxPredictionHistory {
+		p.history = p.history[1:]
+	}
+}
+
+// Reconcile compares the server state with the prediction for the
+// acknowledged input and returns by how much the prediction was off.
+// Since later predictions built on the same mistake, the error can be
+// applied to the current state as a correction.
+func (p *Predictor) Reconcile(ack uint32, server BodyState) (BodyState, bool) {
+	index := -1
+	for i, predicted := range p.history {
+		if predicted.sequence == ack {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return BodyState{}, false
+	}
+	predicted := p.history[index].state
+	p.history = p.history[index+1:]
+	return BodyState{
+		Position:        dprec.Vec3Diff(server.Position, predicted.Position),
+		Rotation:        dprec.QuatProd(server.Rotation, dprec.ConjugateQuat(predicted.Rotation)),
+		Velocity:        dprec.Vec3Diff(server.Velocity, predicted.Velocity),
+		AngularVelocity: dprec.Vec3Diff(server.AngularVelocity, predicted.AngularVelocity),
+	}, true
+}
+
+// Reset forgets all predictions.
+func (p *Predictor) Reset() {
+	p.history = nil
+}
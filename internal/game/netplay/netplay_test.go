@@ -0,0 +1,295 @@
+package netplay
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mokiat/gomath/dprec"
+)
+
+const (
+	testSeed    = 42
+	testTimeout = 2 * time.Second
+)
+
+func TestWelcome(t *testing.T) {
+	server := listen(t, 1)
+	select {
+	case <-server.Joined():
+		t.Fatalf("expected no player to have joined")
+	default:
+	}
+	client := dial(t, server)
+
+	select {
+	case <-server.Joined():
+	case <-time.After(testTimeout):
+		t.Fatalf("timed out waiting for the player to join")
+	}
+	if client.Seed() != testSeed {
+		t.Errorf("expected seed %d, got %d", testSeed, client.Seed())
+	}
+	if client.Player() != HostPlayer+1 {
+		t.Errorf("expected player %d, got %d", HostPlayer+1, client.Player())
+	}
+	eventually(t, "server to list the player", func() bool {
+		players := server.Players()
+		return len(players) == 1 && players[0] == client.Player()
+	})
+}
+
+func TestRejectWhenFull(t *testing.T) {
+	server := listen(t, 1)
+	dial(t, server)
+
+	_, err := Dial(server.Addr().String(), testTimeout)
+	if !errors.Is(err, ErrRejected) {
+		t.Fatalf("expected rejection, got %v", err)
+	}
+}
+
+func TestInputAck(t *testing.T) {
+	server := listen(t, 1)
+	client := dial(t, server)
+
+	var inputs []Input
+	for i := 0; i < 3; i++ {
+		if _, err := client.SendInput(Input{Thrust: float32(i)}); err != nil {
+			t.Fatalf("failed to send input: %v", err)
+		}
+	}
+	// Inputs that were received but not taken yet have not been applied.
+	eventually(t, "server to receive inputs", func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		for _, remote := range server.clients {
+			return remote.received == 3
+		}
+		return false
+	})
+	if err := server.Broadcast(Snapshot{Tick: 1}); err != nil {
+		t.Fatalf("failed to broadcast: %v", err)
+	}
+	eventually(t, "client to receive snapshot", func() bool {
+		_, ok := client.Latest()
+		return ok
+	})
+	if latest, _ := client.Latest(); latest.Ack != 0 {
+		t.Errorf("expected no ack before inputs are taken, got %d", latest.Ack)
+	}
+
+	eventually(t, "server to hand out inputs", func() bool {
+		inputs = append(inputs, server.TakeInputs(client.Player())...)
+		return len(inputs) == 3
+	})
+	for i, input := range inputs {
+		if input.Sequence != uint32(i+1) {
+			t.Errorf("expected sequence %d, got %d", i+1, input.Sequence)
+		}
+	}
+
+	if err := server.Broadcast(Snapshot{Tick: 2}); err != nil {
+		t.Fatalf("failed to broadcast: %v", err)
+	}
+	eventually(t, "client to receive ack", func() bool {
+		latest, ok := client.Latest()
+		return ok && latest.Ack == 3
+	})
+	client.mu.Lock()
+	pending := len(client.pending)
+	client.mu.Unlock()
+	if pending != 0 {
+		t.Errorf("expected no pending inputs, got %d", pending)
+	}
+}
+
+func TestSnapshotDelivery(t *testing.T) {
+	server := listen(t, 1)
+	client := dial(t, server)
+
+	// Enough cows to need several packets.
+	snapshot := Snapshot{
+		Tick:   1,
+		Time:   time.Second,
+		Winner: -1,
+		Players: []PlayerState{
+			{Player: HostPlayer, Score: 3},
+			{Player: client.Player(), Score: 5},
+		},
+		Cows: make([]CowState, 300),
+	}
+	for i := range snapshot.Cows {
+		snapshot.Cows[i] = CowState{
+			Position: dprec.NewVec3(float64(i), 0.0, -float64(i)),
+			Active:   i%2 == 0,
+			PoppedBy: uint8(i % 2),
+		}
+	}
+	if err := server.Broadcast(snapshot); err != nil {
+		t.Fatalf("failed to broadcast: %v", err)
+	}
+
+	var latest Snapshot
+	eventually(t, "client to receive all cows", func() bool {
+		var ok bool
+		latest, ok = client.Latest()
+		if !ok || len(latest.Cows) != len(snapshot.Cows) {
+			return false
+		}
+		last := latest.Cows[len(latest.Cows)-1]
+		return last.Position.X == float64(len(latest.Cows)-1)
+	})
+	if latest.Time != snapshot.Time {
+		t.Errorf("expected time %v, got %v", snapshot.Time, latest.Time)
+	}
+	if state, ok := latest.Player(client.Player()); !ok || state.Score != 5 {
+		t.Errorf("expected score of player to be 5, got %v", state.Score)
+	}
+	for i, cow := range latest.Cows {
+		if cow != snapshot.Cows[i] {
+			t.Fatalf("cow %d: expected %v, got %v", i, snapshot.Cows[i], cow)
+		}
+	}
+}
+
+func TestFinishedAck(t *testing.T) {
+	server := listen(t, 1)
+	client := dial(t, server)
+
+	if err := server.Broadcast(Snapshot{Tick: 1, Finished: true, Winner: -1}); err != nil {
+		t.Fatalf("failed to broadcast: %v", err)
+	}
+	eventually(t, "client to receive the final snapshot", func() bool {
+		latest, ok := client.Latest()
+		return ok && latest.Finished
+	})
+	eventually(t, "server to receive the acknowledgement", func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		for _, client := range server.clients {
+			if !client.finishedAck {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestCloseWithGoneClient(t *testing.T) {
+	server := listen(t, 1)
+	client := dial(t, server)
+
+	// The client stops reading without saying bye, so the final snapshot
+	// is never acknowledged.
+	client.conn.Close()
+	<-client.done
+	if err := server.Broadcast(Snapshot{Tick: 1, Finished: true, Winner: -1}); err != nil {
+		t.Fatalf("failed to broadcast: %v", err)
+	}
+
+	start := time.Now()
+	if err := server.Close(); err != nil {
+		t.Fatalf("failed to close server: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > finishedResendInterval {
+		t.Errorf("expected close not to wait for the client, took %v", elapsed)
+	}
+}
+
+func TestMalformedPackets(t *testing.T) {
+	server := listen(t, 1)
+
+	conn, err := net.Dial("udp", server.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+	for _, packet := range [][]byte{
+		{},
+		{0x00},
+		{0x12, 0x34, kindHello, protocolVersion},
+		{0x67, 0x67, kindInput, 0xFF},
+		{0x67, 0x67, kindSnapshot},
+	} {
+		if _, err := conn.Write(packet); err != nil {
+			t.Fatalf("failed to write: %v", err)
+		}
+	}
+
+	// The server ignores the junk and still accepts players.
+	client := dial(t, server)
+	if client.Player() != HostPlayer+1 {
+		t.Errorf("expected player %d, got %d", HostPlayer+1, client.Player())
+	}
+
+	if _, _, err := openPacket([]byte{0x12, 0x34, kindBye}); !errors.Is(err, ErrMalformedPacket) {
+		t.Errorf("expected malformed packet for wrong magic, got %v", err)
+	}
+	packets, err := encodeSnapshot(Snapshot{Cows: make([]CowState, 10)})
+	if err != nil {
+		t.Fatalf("failed to encode snapshot: %v", err)
+	}
+	r, _, err := openPacket(packets[0][:len(packets[0])-5])
+	if err != nil {
+		t.Fatalf("failed to open packet: %v", err)
+	}
+	if _, err := decodeSnapshot(r); !errors.Is(err, ErrMalformedPacket) {
+		t.Errorf("expected malformed packet for truncated snapshot, got %v", err)
+	}
+}
+
+func TestBye(t *testing.T) {
+	server := listen(t, 2)
+	leaving := dial(t, server)
+	staying := dial(t, server)
+
+	if err := leaving.Close(); err != nil {
+		t.Fatalf("failed to close client: %v", err)
+	}
+	eventually(t, "server to drop the player", func() bool {
+		players := server.Players()
+		return len(players) == 1 && players[0] == staying.Player()
+	})
+
+	if err := server.Close(); err != nil {
+		t.Fatalf("failed to close server: %v", err)
+	}
+	eventually(t, "client to be disconnected", staying.Disconnected)
+}
+
+func listen(t *testing.T, maxClients int) *Server {
+	t.Helper()
+	server, err := Listen("127.0.0.1:0", maxClients, testSeed)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() {
+		server.Close()
+	})
+	return server
+}
+
+func dial(t *testing.T, server *Server) *Client {
+	t.Helper()
+	client, err := Dial(server.Addr().String(), testTimeout)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+	})
+	return client
+}
+
+func eventually(t *testing.T, description string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(testTimeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", description)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
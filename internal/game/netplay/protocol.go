@@ -0,0 +1,370 @@
+package netplay
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mokiat/gomath/dprec"
+)
+
+const (
+	protocolMagic   uint16 = 0x6767
+	protocolVersion uint8  = 3
+
+	// maxPacketSize keeps packets below the usual internet MTU, so that
+	// they are not fragmented.
+	maxPacketSize = 1200
+)
+
+const (
+	kindHello uint8 = iota + 1
+	kindWelcome
+	kindReject
+	kindInput
+	kindSnapshot
+	kindBye
+	kindFinishedAck
+)
+
+// Sizes of the encoded parts of a snapshot packet, in bytes.
+const (
+	snapshotHeaderSize = 2 + 1 + 4 + 4 + 8 + 1 + 1 + 1
+	bodyStateSize      = 4 * (3 + 4 + 3 + 3)
+	playerStateSize    = 1 + 2 + 2*bodyStateSize
+	cowHeaderSize      = 2 + 2 + 2
+	cowStateSize       = 4*3 + 1 + 1
+)
+
+// ErrMalformedPacket indicates that a received packet could not be decoded.
+var ErrMalformedPacket = errors.New("malformed packet")
+
+// Input holds the controls of an airplane for one simulation step, as
+// sent by a client to the server.
+type Input struct {
+	Sequence uint32
+	Thrust   float32
+	Aileron  float32
+	Elevator float32
+	Rudder   float32
+}
+
+// BodyState is the network representation of a physics body.
+type BodyState struct {
+	Position        dprec.Vec3
+	Rotation        dprec.Quat
+	Velocity        dprec.Vec3
+	AngularVelocity dprec.Vec3
+}
+
+// PlayerState is the state of one player within a Snapshot.
+type PlayerState struct {
+	Player   uint8
+	Score    int16
+	Airplane BodyState
+	Ball     BodyState
+}
+
+// CowState is the state of one cow within a Snapshot. Cows are identified
+// by their order of creation, which is the same on all peers.
+type CowState struct {
+	Position dprec.Vec3
+	Active   bool
+
+	// PoppedBy is the player that popped the cow, once it is not Active.
+	PoppedBy uint8
+}
+
+// Snapshot is the authoritative state of the world at a given tick, as
+// sent by the server to the clients.
+type Snapshot struct {
+	Tick uint32
+
+	// Ack is the sequence of the last Input of the receiving client that
+	// the server has applied.
+	Ack uint32
+
+	// Time is the game time of the server, which is also used to line up
+	// snapshots for interpolation.
+	Time time.Duration
+
+	// Finished indicates that the game is over. Winner holds the index of
+	// the winning player or -1 if there is none.
+	Finished bool
+	Winner   int8
+
+	Players []PlayerState
+	Cows    []CowState
+}
+
+// Player returns the state of the specified player.
+func (s *Snapshot) Player(player uint8) (PlayerState, bool) {
+	for _, state := range s.Players {
+		if state.Player == player {
+			return state, true
+		}
+	}
+	return PlayerState{}, false
+}
+
+type packetWriter struct {
+	buf bytes.Buffer
+}
+
+func newPacket(kind uint8) *packetWriter {
+	w := &packetWriter{}
+	w.write(protocolMagic)
+	w.write(kind)
+	return w
+}
+
+func (w *packetWriter) write(value any) {
+	// Writing to a bytes.Buffer does not fail.
+	binary.Write(&w.buf, binary.LittleEndian, value)
+}
+
+func (w *packetWriter) writeVec3(v dprec.Vec3) {
+	w.write([3]float32{float32(v.X), float32(v.Y), float32(v.Z)})
+}
+
+func (w *packetWriter) writeQuat(q dprec.Quat) {
+	w.write([4]float32{float32(q.W), float32(q.X), float32(q.Y), float32(q.Z)})
+}
+
+func (w *packetWriter) writeBody(state BodyState) {
+	w.writeVec3(state.Position)
+	w.writeQuat(state.Rotation)
+	w.writeVec3(state.Velocity)
+	w.writeVec3(state.AngularVelocity)
+}
+
+func (w *packetWriter) bytes() ([]byte, error) {
+	if w.buf.Len() > maxPacketSize {
+		return nil, fmt.Errorf("packet size %d exceeds limit %d", w.buf.Len(), maxPacketSize)
+	}
+	return w.buf.Bytes(), nil
+}
+
+type packetReader struct {
+	in  *bytes.Reader
+	err error
+}
+
+// openPacket validates the header of the packet and returns its kind.
+func openPacket(data []byte) (*packetReader, uint8, error) {
+	r := &packetReader{
+		in: bytes.NewReader(data),
+	}
+	var (
+		magic uint16
+		kind  uint8
+	)
+	r.read(&magic)
+	r.read(&kind)
+	if r.err != nil || magic != protocolMagic {
+		return nil, 0, ErrMalformedPacket
+	}
+	return r, kind, nil
+}
+
+func (r *packetReader) read(target any) {
+	if r.err != nil {
+		return
+	}
+	if err := binary.Read(r.in, binary.LittleEndian, target); err != nil {
+		r.err = ErrMalformedPacket
+	}
+}
+
+func (r *packetReader) readVec3() dprec.Vec3 {
+	var v [3]float32
+	r.read(&v)
+	return dprec.NewVec3(float64(v[0]), float64(v[1]), float64(v[2]))
+}
+
+func (r *packetReader) readQuat() dprec.Quat {
+	var q [4]float32
+	r.read(&q)
+	return dprec.NewQuat(float64(q[0]), float64(q[1]), float64(q[2]), float64(q[3]))
+}
+
+func (r *packetReader) readBody() BodyState {
+	return BodyState{
+		Position:        r.readVec3(),
+		Rotation:        r.readQuat(),
+		Velocity:        r.readVec3(),
+		AngularVelocity: r.readVec3(),
+	}
+}
+
+func encodeHello() ([]byte, error) {
+	w := newPacket(kindHello)
+	w.write(protocolVersion)
+	return w.bytes()
+}
+
+func decodeHello(r *packetReader) (uint8, error) {
+	var version uint8
+	r.read(&version)
+	return version, r.err
+}
+
+func encodeWelcome(player uint8, seed int64) ([]byte, error) {
+	w := newPacket(kindWelcome)
+	w.write(player)
+	w.write(seed)
+	return w.bytes()
+}
+
+func decodeWelcome(r *packetReader) (uint8, int64, error) {
+	var (
+		player uint8
+		seed   int64
+	)
+	r.read(&player)
+	r.read(&seed)
+	return player, seed, r.err
+}
+
+func encodeReject(reason string) ([]byte, error) {
+	w := newPacket(kindReject)
+	w.write(uint8(min(len(reason), 255)))
+	w.buf.WriteString(reason[:min(len(reason), 255)])
+	return w.bytes()
+}
+
+func decodeReject(r *packetReader) (string, error) {
+	var length uint8
+	r.read(&length)
+	reason := make([]byte, length)
+	r.read(reason)
+	return string(reason), r.err
+}
+
+func encodeInputs(inputs []Input) ([]byte, error) {
+	w := newPacket(kindInput)
+	w.write(uint8(len(inputs)))
+	for _, input := range inputs {
+		w.write(input)
+	}
+	return w.bytes()
+}
+
+func decodeInputs(r *packetReader) ([]Input, error) {
+	var count uint8
+	r.read(&count)
+	inputs := make([]Input, count)
+	for i := range inputs {
+		r.read(&inputs[i])
+	}
+	return inputs, r.err
+}
+
+// encodeSnapshot splits the snapshot into as many packets as are needed
+// to fit all cows. Every packet holds the players and a consecutive range
+// of cows, so that each one can be applied on its own if others are lost.
+func encodeSnapshot(snapshot Snapshot) ([][]byte, error) {
+	available := maxPacketSize - snapshotHeaderSize - len(snapshot.Players)*playerStateSize - cowHeaderSize
+	cowsPerPacket := available / cowStateSize
+	if cowsPerPacket <= 0 {
+		return nil, fmt.Errorf("snapshot with %d players does not fit in a packet", len(snapshot.Players))
+	}
+	var packets [][]byte
+	for offset := 0; offset == 0 || offset < len(snapshot.Cows); offset += cowsPerPacket {
+		cows := snapshot.Cows[offset:min(offset+cowsPerPacket, len(snapshot.Cows))]
+		packet, err := encodeSnapshotPart(snapshot, offset, cows)
+		if err != nil {
+			return nil, err
+		}
+		packets = append(packets, packet)
+	}
+	return packets, nil
+}
+
+func encodeSnapshotPart(snapshot Snapshot, offset int, cows []CowState) ([]byte, error) {
+	w := newPacket(kindSnapshot)
+	w.write(snapshot.Tick)
+	w.write(snapshot.Ack)
+	w.write(int64(snapshot.Time))
+	w.write(snapshot.Finished)
+	w.write(snapshot.Winner)
+	w.write(uint8(len(snapshot.Players)))
+	for _, player := range snapshot.Players {
+		w.write(player.Player)
+		w.write(player.Score)
+		w.writeBody(player.Airplane)
+		w.writeBody(player.Ball)
+	}
+	w.write(uint16(len(snapshot.Cows)))
+	w.write(uint16(offset))
+	w.write(uint16(len(cows)))
+	for _, cow := range cows {
+		w.writeVec3(cow.Position)
+		w.write(cow.Active)
+		w.write(cow.PoppedBy)
+	}
+	return w.bytes()
+}
+
+// snapshotPart is a decoded snapshot packet. Its Cows hold only the range
+// that starts at CowOffset, out of CowTotal cows in the whole snapshot.
+type snapshotPart struct {
+	Snapshot
+	CowOffset int
+	CowTotal  int
+}
+
+func decodeSnapshot(r *packetReader) (snapshotPart, error) {
+	var (
+		part        snapshotPart
+		gameTime    int64
+		playerCount uint8
+		cowTotal    uint16
+		cowOffset   uint16
+		cowCount    uint16
+	)
+	r.read(&part.Tick)
+	r.read(&part.Ack)
+	r.read(&gameTime)
+	r.read(&part.Finished)
+	r.read(&part.Winner)
+	part.Time = time.Duration(gameTime)
+	r.read(&playerCount)
+	part.Players = make([]PlayerState, playerCount)
+	for i := range part.Players {
+		player := &part.Players[i]
+		r.read(&player.Player)
+		r.read(&player.Score)
+		player.Airplane = r.readBody()
+		player.Ball = r.readBody()
+	}
+	r.read(&cowTotal)
+	r.read(&cowOffset)
+	r.read(&cowCount)
+	if r.err == nil && int(cowCount)*cowStateSize > r.in.Len() {
+		return snapshotPart{}, ErrMalformedPacket
+	}
+	if int(cowOffset)+int(cowCount) > int(cowTotal) {
+		return snapshotPart{}, ErrMalformedPacket
+	}
+	part.CowTotal = int(cowTotal)
+	part.CowOffset = int(cowOffset)
+	part.Cows = make([]CowState, cowCount)
+	for i := range part.Cows {
+		cow := &part.Cows[i]
+		cow.Position = r.readVec3()
+		r.read(&cow.Active)
+		r.read(&cow.PoppedBy)
+	}
+	return part, r.err
+}
+
+func encodeBye() ([]byte, error) {
+	return newPacket(kindBye).bytes()
+}
+
+func encodeFinishedAck() ([]byte, error) {
+	return newPacket(kindFinishedAck).bytes()
+}
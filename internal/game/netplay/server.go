@@ -0,0 +1,351 @@
+package netplay
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/mokiat/lacking/debug/log"
+)
+
+const (
+	// HostPlayer is the player that is simulated by the server itself.
+	HostPlayer uint8 = 0
+
+	// A client is dropped when it stops sending inputs. The first input is
+	// given more time, since the client may still be loading the level.
+	clientTimeout     = 5 * time.Second
+	firstInputTimeout = 30 * time.Second
+
+	// The final snapshot is repeated until each client acknowledges it,
+	// since the game of a client only ends once it receives it.
+	finishedResendInterval = 100 * time.Millisecond
+	finishedResendTimeout  = 3 * time.Second
+)
+
+// Listen opens a server on the specified UDP address that accepts up to
+// maxClients remote players. The seed is handed to clients, so that any
+// random layout of the level is the same on all peers.
+func Listen(address string, maxClients int, seed int64) (*Server, error) {
+	udpAddr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return nil, err
+	}
+	server := &Server{
+		conn:       conn,
+		maxClients: maxClients,
+		seed:       seed,
+		clients:    make(map[string]*serverClient),
+		joined:     make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go server.receive()
+	return server, nil
+}
+
+// Server is the authoritative side of a network game. It collects the
+// inputs of remote players and sends them snapshots of the world. The
+// simulation itself is run by the caller.
+type Server struct {
+	conn       *net.UDPConn
+	maxClients int
+	seed       int64
+
+	mu       sync.Mutex
+	clients  map[string]*serverClient
+	finished *Snapshot
+	resent   chan struct{}
+
+	joined chan struct{}
+	done   chan struct{}
+}
+
+type serverClient struct {
+	addr        *net.UDPAddr
+	player      uint8
+	lastSeen    time.Time
+	received    uint32
+	ack         uint32
+	inputs      []Input
+	finishedAck bool
+}
+
+// Addr returns the address that the server listens on.
+func (s *Server) Addr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+// Seed returns the seed that is shared with clients.
+func (s *Server) Seed() int64 {
+	return s.seed
+}
+
+// Joined returns a channel that is closed once the first remote player
+// has joined.
+func (s *Server) Joined() <-chan struct{} {
+	return s.joined
+}
+
+// Players returns the remote players that are currently connected.
+func (s *Server) Players() []uint8 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropStale()
+	result := make([]uint8, 0, len(s.clients))
+	for _, client := range s.clients {
+		result = append(result, client.player)
+	}
+	return result
+}
+
+// TakeInputs returns the inputs of the specified player that arrived
+// since the last call, in sequence order. The inputs are considered
+// applied, so the next snapshot acknowledges them.
+func (s *Server) TakeInputs(player uint8) []Input {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, client := range s.clients {
+		if client.player == player {
+			inputs := client.inputs
+			client.inputs = nil
+			if len(inputs) > 0 {
+				client.ack = inputs[len(inputs)-1].Sequence
+			}
+			return inputs
+		}
+	}
+	return nil
+}
+
+// Broadcast sends the snapshot to all clients, acknowledging the last
+// input that was taken from each of them. A finished snapshot is
+// repeated in the background until all clients acknowledge it.
+func (s *Server) Broadcast(snapshot Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropStale()
+	var errs []error
+	for _, client := range s.clients {
+		if err := s.send(client, snapshot); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if snapshot.Finished && s.finished == nil {
+		s.finished = &snapshot
+		s.resent = make(chan struct{})
+		go s.resendFinished()
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Server) send(client *serverClient, snapshot Snapshot) error {
+	snapshot.Ack = client.ack
+	packets, err := encodeSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, packet := range packets {
+		if _, err := s.conn.WriteToUDP(packet, client.addr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Server) resendFinished() {
+	defer close(s.resent)
+	ticker := time.NewTicker(finishedResendInterval)
+	defer ticker.Stop()
+	timeout := time.After(finishedResendTimeout)
+	for {
+		select {
+		case <-timeout:
+			return
+		case <-ticker.C:
+		}
+		s.mu.Lock()
+		pending := false
+		for _, client := range s.clients {
+			if !client.finishedAck {
+				pending = true
+				s.send(client, *s.finished)
+			}
+		}
+		s.mu.Unlock()
+		if !pending {
+			return
+		}
+	}
+}
+
+// Close notifies clients that the game is over and releases the socket.
+// If the game has finished, clients are first given the chance to
+// acknowledge the final snapshot. That happens in the background, so
+// that leaving the game does not wait for clients that are gone.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	resent := s.resent
+	s.mu.Unlock()
+	if resent == nil {
+		return s.shutdown()
+	}
+	go func() {
+		<-resent
+		if err := s.shutdown(); err != nil {
+			log.Warn("Failed to close server: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (s *Server) shutdown() error {
+	s.mu.Lock()
+	if packet, err := encodeBye(); err == nil {
+		for _, client := range s.clients {
+			s.conn.WriteToUDP(packet, client.addr)
+		}
+	}
+	s.clients = make(map[string]*serverClient)
+	s.mu.Unlock()
+
+	err := s.conn.Close()
+	<-s.done
+	return err
+}
+
+func (s *Server) receive() {
+	defer close(s.done)
+	buffer := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buffer)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Warn("Network read error: %v", err)
+			continue
+		}
+		r, kind, err := openPacket(buffer[:n])
+		if err != nil {
+			continue
+		}
+		s.handle(addr, kind, r)
+	}
+}
+
+func (s *Server) handle(addr *net.UDPAddr, kind uint8, r *packetReader) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := addr.String()
+	client, known := s.clients[key]
+	if known {
+		client.lastSeen = time.Now()
+	}
+
+	switch kind {
+	case kindHello:
+		version, err := decodeHello(r)
+		if err != nil {
+			return
+		}
+		if version != protocolVersion {
+			s.reply(addr, encodeReject, "incompatible game version")
+			return
+		}
+		if !known {
+			s.dropStale()
+			player, ok := s.freePlayer()
+			if !ok {
+				s.reply(addr, encodeReject, "game is full")
+				return
+			}
+			client = &serverClient{
+				addr:     addr,
+				player:   player,
+				lastSeen: time.Now(),
+			}
+			s.clients[key] = client
+			log.Info("Player %d joined from %s", player, key)
+			select {
+			case <-s.joined:
+			default:
+				close(s.joined)
+			}
+		}
+		// The welcome is repeated for every hello, in case it was lost.
+		if packet, err := encodeWelcome(client.player, s.seed); err == nil {
+			s.conn.WriteToUDP(packet, addr)
+		}
+
+	case kindInput:
+		if !known {
+			return
+		}
+		inputs, err := decodeInputs(r)
+		if err != nil {
+			return
+		}
+		// Clients repeat unacknowledged inputs, so only newer ones count.
+		for _, input := range inputs {
+			if input.Sequence > client.received {
+				client.inputs = append(client.inputs, input)
+				client.received = input.Sequence
+			}
+		}
+
+	case kindFinishedAck:
+		if known {
+			client.finishedAck = true
+		}
+
+	case kindBye:
+		if known {
+			delete(s.clients, key)
+			log.Info("Player %d left", client.player)
+		}
+	}
+}
+
+func (s *Server) reply(addr *net.UDPAddr, encode func(string) ([]byte, error), reason string) {
+	if packet, err := encode(reason); err == nil {
+		s.conn.WriteToUDP(packet, addr)
+	}
+}
+
+func (s *Server) freePlayer() (uint8, bool) {
+	if len(s.clients) >= s.maxClients {
+		return 0, false
+	}
+	for player := HostPlayer + 1; ; player++ {
+		taken := false
+		for _, client := range s.clients {
+			if client.player == player {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			return player, true
+		}
+	}
+}
+
+func (s *Server) dropStale() {
+	for key, client := range s.clients {
+		timeout := clientTimeout
+		if client.received == 0 {
+			timeout = firstInputTimeout
+		}
+		if time.Since(client.lastSeen) > timeout {
+			delete(s.clients, key)
+			log.Info("Player %d timed out", client.player)
+		}
+	}
+}
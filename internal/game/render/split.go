@@ -0,0 +1,93 @@
+package render
+
+import (
+	"github.com/mokiat/lacking/app"
+	"github.com/mokiat/lacking/debug/metric"
+	"github.com/mokiat/lacking/game"
+	"github.com/mokiat/lacking/game/graphics"
+)
+
+var _ app.Controller = (*SplitController)(nil)
+
+// NewSplitController wraps the specified game controller so that the
+// active scene can be rendered from several cameras, each into its own
+// part of the window.
+func NewSplitController(delegate *game.Controller) *SplitController {
+	return &SplitController{
+		Controller: delegate,
+	}
+}
+
+type SplitController struct {
+	*game.Controller
+
+	width   int
+	height  int
+	cameras []*graphics.Camera
+	prepare func(index int)
+}
+
+// SetCameras configures the cameras that split the window between them.
+// With fewer than two cameras the active camera of the scene is rendered
+// across the whole window.
+//
+// This needs to be called on the UI thread.
+func (c *SplitController) SetCameras(cameras ...*graphics.Camera) {
+	c.cameras = cameras
+}
+
+// SetPrepare specifies a function that is called before the scene is
+// rendered from the camera with the specified index, so that parts of the
+// scene that follow a player (e.g. the shadow casting light) can be moved
+// to the player of that camera.
+//
+// This needs to be called on the UI thread.
+func (c *SplitController) SetPrepare(prepare func(index int)) {
+	c.prepare = prepare
+}
+
+// Viewport returns the part of the window where the camera with the
+// specified index is rendered. Cameras are stacked from the top of the
+// window down.
+func (c *SplitController) Viewport(index int) graphics.Viewport {
+	count := max(1, len(c.cameras))
+	// All parts have the same size, so that the renderer can reuse its
+	// framebuffers between them.
+	height := c.height / count
+	return graphics.NewViewport(0, c.height-(index+1)*height, c.width, height)
+}
+
+func (c *SplitController) OnCreate(window app.Window) {
+	c.Controller.OnCreate(window)
+	c.width, c.height = window.FramebufferSize()
+}
+
+func (c *SplitController) OnFramebufferResize(window app.Window, width, height int) {
+	c.Controller.OnFramebufferResize(window, width, height)
+	c.width = width
+	c.height = height
+}
+
+func (c *SplitController) OnRender(window app.Window) {
+	engine := c.Engine()
+	scene := engine.ActiveScene()
+	if len(c.cameras) < 2 || scene == nil {
+		c.Controller.OnRender(window)
+		return
+	}
+	defer metric.BeginRegion("game").End()
+
+	engine.Update()
+	gfxScene := scene.Graphics()
+	activeCamera := gfxScene.ActiveCamera()
+	for i, camera := range c.cameras {
+		gfxScene.SetActiveCamera(camera)
+		if c.prepare != nil {
+			c.prepare(i)
+		}
+		engine.Render(c.Viewport(i))
+	}
+	gfxScene.SetActiveCamera(activeCamera)
+
+	window.Invalidate() // force redraw
+}
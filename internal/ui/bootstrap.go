@@ -1,25 +1,32 @@
 package internal
 
 import (
+	"github.com/mokiat/ggj2024/internal/game/data"
+	"github.com/mokiat/ggj2024/internal/game/render"
 	"github.com/mokiat/ggj2024/internal/ui/global"
 	"github.com/mokiat/ggj2024/internal/ui/model"
 	"github.com/mokiat/ggj2024/internal/ui/view"
-	"github.com/mokiat/lacking/game"
 	"github.com/mokiat/lacking/ui"
 	co "github.com/mokiat/lacking/ui/component"
 	"github.com/mokiat/lacking/ui/mvc"
 )
 
-func BootstrapApplication(window *ui.Window, gameController *game.Controller) {
+func BootstrapApplication(window *ui.Window, gameController *render.SplitController) {
 	engine := gameController.Engine()
 	eventBus := mvc.NewEventBus()
 
 	scope := co.RootScope(window)
 	scope = co.TypedValueScope(scope, eventBus)
+	resourceSet := engine.CreateResourceSet()
 	scope = co.TypedValueScope(scope, global.Context{
 		AudioAPI:    window.AudioAPI(),
 		Engine:      engine,
-		ResourceSet: engine.CreateResourceSet(),
+		Split:       gameController,
+		ResourceSet: resourceSet,
+		PlayCache:   data.NewPlayCache(window.AudioAPI(), engine, resourceSet),
+	})
+	scope = co.TypedValueScope(scope, global.AppScope{
+		Scope: scope,
 	})
 	co.Initialize(scope, co.New(Bootstrap, nil))
 }
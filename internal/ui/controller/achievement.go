@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/mokiat/ggj2024/internal/game/data"
+)
+
+// NewAchievementTracker creates a tracker that evaluates the specified
+// achievements from the gameplay events on the bus. Achievements that are
+// in the unlocked set are not reported again.
+func NewAchievementTracker(events *GameEventBus, achievements []data.Achievement, unlocked map[string]bool, onUnlock func(achievement data.Achievement)) *AchievementTracker {
+	tracker := &AchievementTracker{
+		achievements: achievements,
+		unlocked:     make(map[string]bool),
+		onUnlock:     onUnlock,
+		pops:         make(map[*Player][]time.Duration),
+		rubbed:       make(map[*Player]bool),
+	}
+	for id, ok := range unlocked {
+		tracker.unlocked[id] = ok
+	}
+	events.CowPopped.Subscribe(tracker.onCowPopped)
+	events.CowRubbed.Subscribe(tracker.onCowRubbed)
+	events.GameFinished.Subscribe(tracker.onGameFinished)
+	return tracker
+}
+
+// AchievementTracker unlocks achievements for the players of this machine
+// based on what happens in the game.
+type AchievementTracker struct {
+	achievements []data.Achievement
+	unlocked     map[string]bool
+	onUnlock     func(achievement data.Achievement)
+
+	pops   map[*Player][]time.Duration
+	rubbed map[*Player]bool
+}
+
+// Reset forgets the progress of the current game, for when it restarts.
+func (t *AchievementTracker) Reset() {
+	clear(t.pops)
+	clear(t.rubbed)
+}
+
+func (t *AchievementTracker) onCowPopped(event CowPopped) {
+	if event.Player.Input == PlayerInputRemote {
+		return
+	}
+	t.pops[event.Player] = append(t.pops[event.Player], event.Time)
+	pops := t.pops[event.Player]
+
+	for _, achievement := range t.achievements {
+		switch achievement.Rule {
+		case data.AchievementRulePopStreak:
+			if len(pops) >= achievement.Count && event.Time-pops[len(pops)-achievement.Count] <= achievement.Window {
+				t.unlock(achievement)
+			}
+		case data.AchievementRuleInvertedPop:
+			if event.Inverted {
+				t.unlock(achievement)
+			}
+		}
+	}
+}
+
+func (t *AchievementTracker) onCowRubbed(event CowRubbed) {
+	t.rubbed[event.Player] = true
+}
+
+func (t *AchievementTracker) onGameFinished(event GameFinished) {
+	if event.Outcome != GameOutcomeVictory {
+		return
+	}
+	for _, achievement := range t.achievements {
+		switch achievement.Rule {
+		case data.AchievementRuleCleanVictory:
+			for _, player := range event.Winners {
+				if player.Input != PlayerInputRemote && !t.rubbed[player] {
+					t.unlock(achievement)
+					break
+				}
+			}
+		case data.AchievementRuleTimeLeft:
+			if event.Countdown && event.TimeLeft >= achievement.Window && t.hasLocalWinner(event) {
+				t.unlock(achievement)
+			}
+		}
+	}
+}
+
+func (t *AchievementTracker) hasLocalWinner(event GameFinished) bool {
+	for _, player := range event.Winners {
+		if player.Input != PlayerInputRemote {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *AchievementTracker) unlock(achievement data.Achievement) {
+	if t.unlocked[achievement.ID] {
+		return
+	}
+	t.unlocked[achievement.ID] = true
+	t.onUnlock(achievement)
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"math"
 
+	"github.com/mokiat/ggj2024/internal/game/data"
 	"github.com/mokiat/gomath/dprec"
 	"github.com/mokiat/lacking/app"
 	"github.com/mokiat/lacking/game"
@@ -21,9 +22,27 @@ var (
 	maxRudderAngle   = dprec.Degrees(20) // TODO
 	maxThrust        = 9.8 * 1.5         // 1.5g
 	thrustRampUp     = maxThrust / 2.0
+
+	// mouseThrottleStep is the portion of the max thrust that one step
+	// of the scroll wheel adds or removes.
+	mouseThrottleStep = 0.1
+
+	// The airplane starts to stall when it flies slower than stallSpeed
+	// or when its nose points more than stallAngle away from where it
+	// is going.
+	stallSpeed = 6.0
+	stallAngle = dprec.Degrees(25)
 )
 
-func NewAirplane(physicsScene *physics.Scene, ecsScene *ecs.Scene, model *game.Model, position dprec.Vec3) *Airplane {
+// AirplaneModel holds the nodes that an Airplane is built from. It is
+// satisfied by game.Model, but can also be a plain node hierarchy, so
+// that the airplane can be simulated without graphics.
+type AirplaneModel interface {
+	Root() *hierarchy.Node
+	FindNode(name string) *hierarchy.Node
+}
+
+func NewAirplane(physicsScene *physics.Scene, ecsScene *ecs.Scene, model AirplaneModel, position dprec.Vec3) *Airplane {
 	var (
 		airplaneMass            = 1500.0
 		airplaneMomentOfInertia = physics.SymmetricMomentOfInertia(1500.0 / 2.0)
@@ -43,6 +62,27 @@ func NewAirplane(physicsScene *physics.Scene, ecsScene *ecs.Scene, model *game.M
 
 	collisionGroup := physics.NewCollisionGroup()
 
+	wingTransform := physics.NewTransform(
+		dprec.NewVec3(0.0, 0.0, -1.7),
+		dprec.RotationQuat(dprec.Degrees(-5), dprec.BasisXVec3()),
+	)
+	wingShape := physics.NewSurfaceAerodynamicShape(16.0, 0.1, 2.4)
+	aileronTransform := physics.NewTransform(
+		dprec.NewVec3(0.0, 0.0, -0.4),
+		dprec.IdentityQuat(),
+	)
+	aileronShape := physics.NewSurfaceAerodynamicShape(3.0, 0.1, 1.1)
+	elevatorTransform := physics.NewTransform(
+		dprec.NewVec3(0.0, 0.0, -0.4),
+		dprec.IdentityQuat(),
+	)
+	elevatorShape := physics.NewSurfaceAerodynamicShape(4.4, 0.1, 0.8)
+	rudderTransform := physics.NewTransform(
+		dprec.NewVec3(0.0, 0.0, 0.0),
+		dprec.RotationQuat(dprec.Degrees(90), dprec.BasisZVec3()),
+	)
+	rudderShape := physics.NewSurfaceAerodynamicShape(2.0, 0.1, 1.0)
+
 	airplaneBodyDef := physicsScene.Engine().CreateBodyDefinition(physics.BodyDefinitionInfo{
 		Mass:                   airplaneMass,
 		MomentOfInertia:        airplaneMomentOfInertia,
@@ -73,14 +113,7 @@ func NewAirplane(physicsScene *physics.Scene, ecsScene *ecs.Scene, model *game.M
 			),
 		},
 		AerodynamicShapes: []physics.AerodynamicShape{
-			// wings
-			physics.NewAerodynamicShape(
-				physics.NewTransform(
-					dprec.NewVec3(0.0, 0.0, -1.7),
-					dprec.RotationQuat(dprec.Degrees(-5), dprec.BasisXVec3()),
-				),
-				physics.NewSurfaceAerodynamicShape(16.0, 0.1, 2.4),
-			),
+			physics.NewAerodynamicShape(wingTransform, wingShape), // wings
 		},
 	})
 
@@ -91,13 +124,7 @@ func NewAirplane(physicsScene *physics.Scene, ecsScene *ecs.Scene, model *game.M
 		AngularDragFactor:      0.0, // TODO
 		RestitutionCoefficient: 0.0,
 		AerodynamicShapes: []physics.AerodynamicShape{
-			physics.NewAerodynamicShape(
-				physics.NewTransform(
-					dprec.NewVec3(0.0, 0.0, -0.4),
-					dprec.IdentityQuat(),
-				),
-				physics.NewSurfaceAerodynamicShape(3.0, 0.1, 1.1),
-			),
+			physics.NewAerodynamicShape(aileronTransform, aileronShape),
 		},
 	})
 
@@ -108,13 +135,7 @@ func NewAirplane(physicsScene *physics.Scene, ecsScene *ecs.Scene, model *game.M
 		AngularDragFactor:      0.0, // TODO
 		RestitutionCoefficient: 0.0,
 		AerodynamicShapes: []physics.AerodynamicShape{
-			physics.NewAerodynamicShape(
-				physics.NewTransform(
-					dprec.NewVec3(0.0, 0.0, -0.4),
-					dprec.IdentityQuat(),
-				),
-				physics.NewSurfaceAerodynamicShape(4.4, 0.1, 0.8),
-			),
+			physics.NewAerodynamicShape(elevatorTransform, elevatorShape),
 		},
 	})
 
@@ -125,13 +146,7 @@ func NewAirplane(physicsScene *physics.Scene, ecsScene *ecs.Scene, model *game.M
 		AngularDragFactor:      0.0, // TODO
 		RestitutionCoefficient: 0.0,
 		AerodynamicShapes: []physics.AerodynamicShape{
-			physics.NewAerodynamicShape(
-				physics.NewTransform(
-					dprec.NewVec3(0.0, 0.0, 0.0),
-					dprec.RotationQuat(dprec.Degrees(90), dprec.BasisZVec3()),
-				),
-				physics.NewSurfaceAerodynamicShape(2.0, 0.1, 1.0),
-			),
+			physics.NewAerodynamicShape(rudderTransform, rudderShape),
 		},
 	})
 
@@ -346,7 +361,7 @@ func NewAirplane(physicsScene *physics.Scene, ecsScene *ecs.Scene, model *game.M
 		Node: airplaneNode,
 	})
 
-	return &Airplane{
+	airplane := &Airplane{
 		Entity:        entity,
 		Node:          airplaneNode,
 		PropellerNode: properllerNode,
@@ -361,7 +376,35 @@ func NewAirplane(physicsScene *physics.Scene, ecsScene *ecs.Scene, model *game.M
 
 		TargetThrust: maxThrust / 1.5,
 		Thrust:       maxThrust,
+
+		snapshot: newBodySnapshot(
+			airplaneBody,
+			counterweightBody,
+			leftAileronBody,
+			rightAileronBody,
+			elevatorBody,
+			rudderBody,
+		),
+
+		debugSurfaces: []debugSurface{
+			{body: airplaneBody, transform: wingTransform, shape: wingShape},
+			{body: leftAileronBody, transform: aileronTransform, shape: aileronShape},
+			{body: rightAileronBody, transform: aileronTransform, shape: aileronShape},
+			{body: elevatorBody, transform: elevatorTransform, shape: elevatorShape},
+			{body: rudderBody, transform: rudderTransform, shape: rudderShape},
+		},
+		debugLinks: []debugLink{
+			{primary: airplaneBody, primaryOffset: counterweightRelativePosition, secondary: counterweightBody, secondaryOffset: dprec.ZeroVec3()},
+			{primary: airplaneBody, primaryOffset: leftAileronRelativePosition, secondary: leftAileronBody, secondaryOffset: dprec.ZeroVec3()},
+			{primary: airplaneBody, primaryOffset: rightAileronRelativePosition, secondary: rightAileronBody, secondaryOffset: dprec.ZeroVec3()},
+			{primary: airplaneBody, primaryOffset: elevatorRelativePosition, secondary: elevatorBody, secondaryOffset: dprec.ZeroVec3()},
+			{primary: airplaneBody, primaryOffset: rudderRelativePosition, secondary: rudderBody, secondaryOffset: dprec.ZeroVec3()},
+		},
 	}
+	ecs.AttachComponent(entity, &AirplaneComponent{
+		Airplane: airplane,
+	})
+	return airplane
 }
 
 type Airplane struct {
@@ -383,6 +426,41 @@ type Airplane struct {
 	AileronAngle  dprec.Angle
 	ElevatorAngle dprec.Angle
 	RudderAngle   dprec.Angle
+
+	snapshot      bodySnapshot
+	debugSurfaces []debugSurface
+	debugLinks    []debugLink
+}
+
+// Reset puts the airplane back where it was created, flying with its
+// initial speed and neutral controls.
+func (a *Airplane) Reset() {
+	a.snapshot.Restore()
+	a.TargetThrust = maxThrust / 1.5
+	a.Thrust = maxThrust
+	a.AileronAngle = 0.0
+	a.ElevatorAngle = 0.0
+	a.RudderAngle = 0.0
+}
+
+// Inverted returns whether the airplane is flying upside down.
+func (a *Airplane) Inverted() bool {
+	return a.Body.Rotation().OrientationY().Y < 0.0
+}
+
+// StallAmount returns how deep the airplane is in a stall, from 0.0 when it
+// flies normally to 1.0 when it barely flies at all.
+func (a *Airplane) StallAmount() float64 {
+	velocity := a.Body.Velocity()
+	forward := dprec.UnitVec3(a.Body.Rotation().OrientationZ())
+	airspeed := dprec.Vec3Dot(velocity, forward)
+	amount := dprec.Clamp((stallSpeed-airspeed)/stallSpeed, 0.0, 1.0)
+	if velocity.Length() > 0.1 {
+		cos := dprec.Clamp(dprec.Vec3Dot(dprec.UnitVec3(velocity), forward), -1.0, 1.0)
+		angle := dprec.Radians(math.Acos(cos))
+		amount = max(amount, dprec.Clamp(float64((angle-stallAngle)/stallAngle), 0.0, 1.0))
+	}
+	return amount
 }
 
 func (a *Airplane) UpdatePhysics(elapsedSeconds float64) {
@@ -576,3 +654,103 @@ func (c *AirplaneKeyboardController) Update(elapsedSeconds float64) {
 	}
 
 }
+
+func NewAirplaneMouseController(airplane *Airplane, camera *hierarchy.Node, settings data.ControlSettings) *AirplaneMouseController {
+	return &AirplaneMouseController{
+		airplane: airplane,
+		camera:   camera,
+		settings: settings,
+	}
+}
+
+// AirplaneMouseController steers the airplane towards the point under the
+// cursor. The cursor picks a direction through the camera and an
+// instructor works the controls to get the nose pointing there, so the
+// airplane flies straight once the cursor is in the center of the view.
+type AirplaneMouseController struct {
+	airplane *Airplane
+	camera   *hierarchy.Node
+	settings data.ControlSettings
+
+	// cursor is the position of the cursor within the view of the player,
+	// where the center is at zero and the edges are at one.
+	cursor dprec.Vec2
+	aspect float64
+}
+
+// OnMouseEvent handles a mouse event that happened within the specified
+// view bounds of the player.
+func (c *AirplaneMouseController) OnMouseEvent(event ui.MouseEvent, bounds ui.Bounds) bool {
+	switch event.Action {
+	case ui.MouseActionMove:
+		if bounds.Width <= 0 || bounds.Height <= 0 {
+			return false
+		}
+		halfWidth := float64(bounds.Width) / 2.0
+		halfHeight := float64(bounds.Height) / 2.0
+		c.cursor = dprec.NewVec2(
+			dprec.Clamp((float64(event.X-bounds.X)-halfWidth)/halfWidth, -1.0, 1.0),
+			dprec.Clamp((halfHeight-float64(event.Y-bounds.Y))/halfHeight, -1.0, 1.0),
+		)
+		if c.settings.InvertMouse {
+			c.cursor.Y = -c.cursor.Y
+		}
+		c.aspect = halfWidth / halfHeight
+		return true
+	case ui.MouseActionScroll:
+		c.airplane.TargetThrust += float64(event.ScrollY) * mouseThrottleStep * maxThrust
+		c.airplane.TargetThrust = dprec.Clamp(c.airplane.TargetThrust, 0.0, maxThrust)
+		return true
+	}
+	return false
+}
+
+func (c *AirplaneMouseController) Update(elapsedSeconds float64) {
+	var (
+		maxPitch       = dprec.Degrees(45)
+		maxRoll        = dprec.Degrees(60)
+		bankGain       = 2.0
+		rudderGain     = 1.0
+		halfFoV        = dprec.Degrees(30) // the vertical camera FoV is 60 degrees
+		cursorDeadzone = 0.02
+	)
+
+	cursor := c.cursor
+	if cursor.Length() < cursorDeadzone {
+		cursor = dprec.ZeroVec2()
+	}
+	cursor = dprec.Vec2Prod(cursor, c.settings.MouseSensitivity)
+
+	// Cameras look along their negative Z axis.
+	tanY := dprec.Tan(halfFoV)
+	tanX := tanY * max(c.aspect, 1.0)
+	cameraMatrix := c.camera.AbsoluteMatrix()
+	target := dprec.UnitVec3(dprec.Vec3Sum(
+		dprec.Vec3Sum(
+			dprec.Vec3Prod(dprec.UnitVec3(cameraMatrix.OrientationX()), cursor.X*tanX),
+			dprec.Vec3Prod(dprec.UnitVec3(cameraMatrix.OrientationY()), cursor.Y*tanY),
+		),
+		dprec.InverseVec3(dprec.UnitVec3(cameraMatrix.OrientationZ())),
+	))
+
+	// The wings point along the X axis of the airplane, with the left one
+	// being on the positive side.
+	rotation := c.airplane.Body.Rotation()
+	local := dprec.QuatVec3Rotation(dprec.ConjugateQuat(rotation), target)
+	side := math.Atan2(-local.X, local.Z)
+
+	targetRoll := dprec.Clamp(dprec.Radians(side*bankGain), -maxRoll, maxRoll)
+	directionRoll := dprec.Radians(math.Asin(dprec.Vec3Dot(
+		dprec.UnitVec3(rotation.OrientationX()),
+		dprec.BasisYVec3(),
+	)))
+	c.airplane.AileronAngle = dprec.Clamp(targetRoll-directionRoll, -maxAileronAngle, maxAileronAngle)
+	c.airplane.RudderAngle = dprec.Clamp(dprec.Radians(side*rudderGain), -maxRudderAngle, maxRudderAngle)
+
+	direction := c.airplane.Body.Velocity()
+	if direction.Length() > 0.1 {
+		targetPitch := dprec.Clamp(dprec.Radians(math.Atan2(target.Y, dprec.NewVec2(target.X, target.Z).Length())), -maxPitch, maxPitch)
+		directionPitch := dprec.Radians(math.Atan2(direction.Y, dprec.NewVec2(direction.X, direction.Z).Length()))
+		c.airplane.ElevatorAngle = dprec.Clamp(targetPitch-directionPitch, -maxElevatorAngle, maxElevatorAngle)
+	}
+}
@@ -1,15 +1,17 @@
 package controller
 
 import (
+	"github.com/mokiat/ggj2024/internal/game/data"
 	"github.com/mokiat/gomath/dprec"
 	"github.com/mokiat/lacking/game"
+	"github.com/mokiat/lacking/game/ecs"
 	"github.com/mokiat/lacking/game/hierarchy"
 	"github.com/mokiat/lacking/game/physics"
 	"github.com/mokiat/lacking/game/physics/collision"
 	"github.com/mokiat/lacking/game/physics/constraint"
 )
 
-func NewBall(physicsScene *physics.Scene, airplane *Airplane, model *game.Model) *Ball {
+func NewBall(physicsScene *physics.Scene, ecsScene *ecs.Scene, airplane *Airplane, model *game.Model, payload data.Payload) *Ball {
 	hingeUpperNode := model.FindNode("UpperNode")
 	hingeLowerNode := model.FindNode("LowerNode")
 	ballNode := model.FindNode("BallNode")
@@ -25,22 +27,22 @@ func NewBall(physicsScene *physics.Scene, airplane *Airplane, model *game.Model)
 		AerodynamicShapes:      []physics.AerodynamicShape{}, // TODO
 	})
 
+	ballTransform := physics.NewTransform(
+		dprec.NewVec3(0.0, 0.0, 0.0),
+		dprec.RotationQuat(dprec.Degrees(20), dprec.BasisXVec3()),
+	)
+	ballShape := physics.NewSurfaceAerodynamicShape(1.0, 0.1, 0.5)
+
 	ballBodyDef := physicsScene.Engine().CreateBodyDefinition(physics.BodyDefinitionInfo{
-		Mass:                   10.0,
-		MomentOfInertia:        physics.SymmetricMomentOfInertia(10.0 / 2.0),
+		Mass:                   payload.Mass,
+		MomentOfInertia:        physics.SymmetricMomentOfInertia(payload.Mass / 2.0),
 		FrictionCoefficient:    0.0,
 		RestitutionCoefficient: 0.0,
 		CollisionGroup:         airplane.CollisionGroup,
 		DragFactor:             1.0,
 		AngularDragFactor:      0.0,
 		AerodynamicShapes: []physics.AerodynamicShape{
-			physics.NewAerodynamicShape(
-				physics.NewTransform(
-					dprec.NewVec3(0.0, 0.0, 0.0),
-					dprec.RotationQuat(dprec.Degrees(20), dprec.BasisXVec3()),
-				),
-				physics.NewSurfaceAerodynamicShape(1.0, 0.1, 0.5),
-			),
+			physics.NewAerodynamicShape(ballTransform, ballShape),
 		},
 		CollisionSpheres: []collision.Sphere{
 			collision.NewSphere(dprec.ZeroVec3(), 2.75),
@@ -108,13 +110,39 @@ func NewBall(physicsScene *physics.Scene, airplane *Airplane, model *game.Model)
 		Body: ballBody,
 	})
 
-	return &Ball{
-		Body: ballBody,
-		Node: ballNode,
+	ball := &Ball{
+		Entity: ecsScene.CreateEntity(),
+		Body:   ballBody,
+		Node:   ballNode,
+
+		snapshot: newBodySnapshot(hingeBody, ballBody),
+
+		debugSurfaces: []debugSurface{
+			{body: ballBody, transform: ballTransform, shape: ballShape},
+		},
+		debugLinks: []debugLink{
+			// The rod is anchored at the centers of both bodies.
+			{primary: hingeBody, primaryOffset: dprec.ZeroVec3(), secondary: ballBody, secondaryOffset: dprec.ZeroVec3()},
+		},
 	}
+	ecs.AttachComponent(ball.Entity, &BallComponent{
+		Ball: ball,
+	})
+	return ball
 }
 
 type Ball struct {
-	Body physics.Body
-	Node *hierarchy.Node
+	Entity *ecs.Entity
+	Body   physics.Body
+	Node   *hierarchy.Node
+
+	snapshot      bodySnapshot
+	debugSurfaces []debugSurface
+	debugLinks    []debugLink
+}
+
+// Reset puts the ball back where it was created, hanging still below the
+// airplane.
+func (b *Ball) Reset() {
+	b.snapshot.Restore()
 }
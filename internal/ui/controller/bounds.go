@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/mokiat/ggj2024/internal/game/data"
+)
+
+const (
+	BoundsEventNone BoundsEvent = iota
+	BoundsEventLeft
+	BoundsEventReturned
+	BoundsEventExpired
+)
+
+// BoundsEvent indicates how the position of the airplane relative to the
+// level bounds has changed.
+type BoundsEvent int
+
+func NewBoundsSystem(bounds data.Bounds, airplane *Airplane) *BoundsSystem {
+	return &BoundsSystem{
+		bounds:   bounds,
+		airplane: airplane,
+	}
+}
+
+// BoundsSystem keeps track of how long the airplane has been outside of
+// the level bounds.
+type BoundsSystem struct {
+	bounds   data.Bounds
+	airplane *Airplane
+
+	outside  bool
+	duration time.Duration
+}
+
+func (s *BoundsSystem) Bounds() data.Bounds {
+	return s.bounds
+}
+
+// OutOfBounds returns whether the airplane is outside of the level bounds
+// and how much time the player has to return.
+func (s *BoundsSystem) OutOfBounds() (time.Duration, bool) {
+	if !s.outside {
+		return 0, false
+	}
+	return max(0, s.bounds.Grace-s.duration), true
+}
+
+func (s *BoundsSystem) Reset() {
+	s.outside = false
+	s.duration = 0
+}
+
+func (s *BoundsSystem) Update(elapsedTime time.Duration) BoundsEvent {
+	inside := s.bounds.Contains(s.airplane.Body.Position())
+	switch {
+	case inside && s.outside:
+		s.Reset()
+		return BoundsEventReturned
+	case inside:
+		return BoundsEventNone
+	case !s.outside:
+		s.outside = true
+		s.duration = 0
+		return BoundsEventLeft
+	}
+	expired := s.duration >= s.bounds.Grace
+	s.duration += elapsedTime
+	if !expired && s.duration >= s.bounds.Grace {
+		return BoundsEventExpired
+	}
+	return BoundsEventNone
+}
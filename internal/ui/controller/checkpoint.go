@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/mokiat/ggj2024/internal/game/data"
+)
+
+const (
+	checkpointInterval        = 2 * time.Second
+	checkpointMinAltitude     = 30.0
+	checkpointMinSpeed        = 5.0
+	checkpointMaxAngularSpeed = 1.0
+	checkpointMinUpright      = 0.5
+
+	respawnInvulnerability = 3 * time.Second
+	respawnPenalty         = 5 * time.Second
+
+	// respawnImpactSpeed is the descent speed from which a crash wrecks the
+	// airplane. Lighter crashes only shake it.
+	respawnImpactSpeed = 10.0
+)
+
+// Checkpoint is a safe state of the airplane and the ball that the player
+// can be returned to.
+type Checkpoint struct {
+	airplane     bodySnapshot
+	ball         bodySnapshot
+	thrust       float64
+	targetThrust float64
+}
+
+func captureCheckpoint(airplane *Airplane, ball *Ball) Checkpoint {
+	return Checkpoint{
+		airplane:     newBodySnapshot(airplane.snapshot.bodies...),
+		ball:         newBodySnapshot(ball.snapshot.bodies...),
+		thrust:       airplane.Thrust,
+		targetThrust: airplane.TargetThrust,
+	}
+}
+
+func NewCheckpointSystem(airplane *Airplane, ball *Ball, bounds data.Bounds) *CheckpointSystem {
+	return &CheckpointSystem{
+		airplane:   airplane,
+		ball:       ball,
+		bounds:     bounds,
+		checkpoint: captureCheckpoint(airplane, ball),
+	}
+}
+
+// CheckpointSystem periodically records the state of the airplane and the
+// ball while the flight is stable, so that a crash does not require a full
+// restart.
+type CheckpointSystem struct {
+	airplane *Airplane
+	ball     *Ball
+	bounds   data.Bounds
+
+	checkpoint      Checkpoint
+	sinceCheckpoint time.Duration
+	invulnerable    time.Duration
+}
+
+// Invulnerable returns whether the airplane has recently respawned and
+// crashes should be ignored.
+func (s *CheckpointSystem) Invulnerable() bool {
+	return s.invulnerable > 0
+}
+
+// Reset forgets all checkpoints and records the current state as the
+// initial one.
+func (s *CheckpointSystem) Reset() {
+	s.checkpoint = captureCheckpoint(s.airplane, s.ball)
+	s.sinceCheckpoint = 0
+	s.invulnerable = 0
+}
+
+// Respawn restores the last checkpoint and makes the airplane invulnerable
+// for a short while.
+func (s *CheckpointSystem) Respawn() {
+	s.checkpoint.airplane.Restore()
+	s.checkpoint.ball.Restore()
+	s.airplane.Thrust = s.checkpoint.thrust
+	s.airplane.TargetThrust = s.checkpoint.targetThrust
+	s.airplane.AileronAngle = 0.0
+	s.airplane.ElevatorAngle = 0.0
+	s.airplane.RudderAngle = 0.0
+	s.sinceCheckpoint = 0
+	s.invulnerable = respawnInvulnerability
+}
+
+func (s *CheckpointSystem) Update(elapsedTime time.Duration) {
+	if s.invulnerable > 0 {
+		s.invulnerable -= elapsedTime
+		return
+	}
+	s.sinceCheckpoint += elapsedTime
+	if s.sinceCheckpoint < checkpointInterval || !s.isStable() {
+		return
+	}
+	s.checkpoint = captureCheckpoint(s.airplane, s.ball)
+	s.sinceCheckpoint = 0
+}
+
+func (s *CheckpointSystem) isStable() bool {
+	body := s.airplane.Body
+	if !s.bounds.Contains(body.Position()) {
+		return false
+	}
+	if body.Position().Y < checkpointMinAltitude {
+		return false
+	}
+	if body.Velocity().Length() < checkpointMinSpeed {
+		return false
+	}
+	if body.AngularVelocity().Length() > checkpointMaxAngularSpeed {
+		return false
+	}
+	return body.Rotation().OrientationY().Y >= checkpointMinUpright
+}
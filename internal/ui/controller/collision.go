@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"github.com/mokiat/gomath/dprec"
+	"github.com/mokiat/lacking/game/ecs"
+	"github.com/mokiat/lacking/game/physics"
+)
+
+// crashMinImpactSpeed is the speed towards the ground below which terrain
+// contacts are considered touches rather than crashes. Since the speed is
+// measured vertically, it also filters out shallow grazes at high speed.
+const crashMinImpactSpeed = 3.0
+
+var (
+	AirplaneComponentID = ecs.NewComponentTypeID()
+	BallComponentID     = ecs.NewComponentTypeID()
+)
+
+type AirplaneComponent struct {
+	Airplane *Airplane
+}
+
+func (*AirplaneComponent) TypeID() ecs.ComponentTypeID {
+	return AirplaneComponentID
+}
+
+type BallComponent struct {
+	Ball *Ball
+}
+
+func (*BallComponent) TypeID() ecs.ComponentTypeID {
+	return BallComponentID
+}
+
+// BallCowContact is published when the ball starts touching a cow.
+type BallCowContact struct {
+	Ball *Ball
+	Cow  *Cow
+}
+
+// AirplaneCowContact is published when the airplane starts rubbing a cow.
+type AirplaneCowContact struct {
+	Airplane *Airplane
+	Cow      *Cow
+}
+
+// AirplaneCrash is published when the airplane hits the terrain.
+type AirplaneCrash struct {
+	Airplane *Airplane
+	Prop     physics.Prop
+
+	// ImpactSpeed is the speed at which the airplane was descending.
+	ImpactSpeed float64
+}
+
+// CollisionBus distributes gameplay collisions to interested parties.
+type CollisionBus struct {
+	BallCow       Topic[BallCowContact]
+	AirplaneCow   Topic[AirplaneCowContact]
+	AirplaneCrash Topic[AirplaneCrash]
+}
+
+func NewCollisionDispatcher(physicsScene *physics.Scene) *CollisionDispatcher {
+	result := &CollisionDispatcher{
+		entities: make(map[physics.Body]*ecs.Entity),
+	}
+	result.dbSubscription = physicsScene.SubscribeDoubleBodyCollision(result.onDoubleBodyCollision)
+	result.sbSubscription = physicsScene.SubscribeSingleBodyCollision(result.onSingleBodyCollision)
+	return result
+}
+
+// CollisionDispatcher translates physics collisions into CollisionBus events
+// by looking up the entities that own the colliding bodies.
+type CollisionDispatcher struct {
+	Bus CollisionBus
+
+	entities map[physics.Body]*ecs.Entity
+
+	dbSubscription *physics.DoubleBodyCollisionSubscription
+	sbSubscription *physics.SingleBodyCollisionSubscription
+}
+
+func (d *CollisionDispatcher) Register(body physics.Body, entity *ecs.Entity) {
+	d.entities[body] = entity
+}
+
+func (d *CollisionDispatcher) Unregister(body physics.Body) {
+	delete(d.entities, body)
+}
+
+func (d *CollisionDispatcher) Delete() {
+	d.dbSubscription.Delete()
+	d.sbSubscription.Delete()
+	clear(d.entities)
+}
+
+func (d *CollisionDispatcher) onDoubleBodyCollision(first, second physics.Body, active bool) {
+	if !active {
+		return
+	}
+	firstEntity, ok := d.entities[first]
+	if !ok {
+		return
+	}
+	secondEntity, ok := d.entities[second]
+	if !ok {
+		return
+	}
+	d.dispatch(firstEntity, secondEntity)
+	d.dispatch(secondEntity, firstEntity)
+}
+
+func (d *CollisionDispatcher) dispatch(source, target *ecs.Entity) {
+	var (
+		airplaneComp *AirplaneComponent
+		ballComp     *BallComponent
+		cowComp      *CowComponent
+	)
+	isAirplane := ecs.FetchComponent(source, &airplaneComp)
+	isBall := ecs.FetchComponent(source, &ballComp)
+	if !isAirplane && !isBall {
+		return
+	}
+
+	if !ecs.FetchComponent(target, &cowComp) || !cowComp.Cow.Active {
+		return
+	}
+	if isBall {
+		d.Bus.BallCow.Publish(BallCowContact{
+			Ball: ballComp.Ball,
+			Cow:  cowComp.Cow,
+		})
+	}
+	if isAirplane {
+		d.Bus.AirplaneCow.Publish(AirplaneCowContact{
+			Airplane: airplaneComp.Airplane,
+			Cow:      cowComp.Cow,
+		})
+	}
+}
+
+func (d *CollisionDispatcher) onSingleBodyCollision(body physics.Body, prop physics.Prop, active bool) {
+	if !active {
+		return
+	}
+	entity, ok := d.entities[body]
+	if !ok {
+		return
+	}
+	var airplaneComp *AirplaneComponent
+	if ecs.FetchComponent(entity, &airplaneComp) {
+		impactSpeed := max(0.0, -dprec.Vec3Dot(body.Velocity(), dprec.BasisYVec3()))
+		if impactSpeed < crashMinImpactSpeed {
+			return
+		}
+		d.Bus.AirplaneCrash.Publish(AirplaneCrash{
+			Airplane:    airplaneComp.Airplane,
+			Prop:        prop,
+			ImpactSpeed: impactSpeed,
+		})
+	}
+}
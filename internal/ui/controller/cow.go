@@ -1,110 +1,285 @@
 package controller
 
 import (
+	"math"
 	"math/rand"
 	"time"
 
+	"github.com/mokiat/ggj2024/internal/game/data"
 	"github.com/mokiat/gomath/dprec"
 	"github.com/mokiat/lacking/game"
+	"github.com/mokiat/lacking/game/ecs"
+	"github.com/mokiat/lacking/game/hierarchy"
 	"github.com/mokiat/lacking/game/physics"
 	"github.com/mokiat/lacking/game/physics/collision"
 	"github.com/mokiat/lacking/game/physics/constraint"
 )
 
-func NewCowSpawner(scene *game.Scene, modelDef, burstDef *game.ModelDefinition) *CowSpawner {
-	bodyDef := scene.Physics().Engine().CreateBodyDefinition(physics.BodyDefinitionInfo{
-		Mass:                   100.0,
-		MomentOfInertia:        physics.SymmetricMomentOfInertia(100.0 / 2.0),
-		FrictionCoefficient:    1.0,
-		RestitutionCoefficient: 1.0,
-		DragFactor:             0.0,
-		AngularDragFactor:      0.0,
-		CollisionSpheres: []collision.Sphere{
-			collision.NewSphere(dprec.ZeroVec3(), 7.5),
-		},
-	})
+const (
+	cowWobbleDuration = 600 * time.Millisecond
+	cowWobbleAmount   = 0.3
+	cowWobbleCycles   = 3.0
+
+	// cowWobbleSoundCooldown keeps a cow that is pushed around by the ball
+	// from playing its wobble sound on every contact.
+	cowWobbleSoundCooldown = time.Second
+)
 
+const (
+	CowHitWobble CowHitResult = iota
+	CowHitDamage
+	CowHitPop
+)
+
+type CowHitResult int
+
+func NewCowSpawner(scene *game.Scene, models map[string]*game.ModelDefinition) *CowSpawner {
 	return &CowSpawner{
-		scene:    scene,
-		bodyDef:  bodyDef,
-		burstDef: burstDef,
-		modelDef: modelDef,
+		scene:       scene,
+		bodyDefs:    make(map[string]*physics.BodyDefinition),
+		models:      models,
+		effectPools: make(map[string]*EffectPool),
 	}
 }
 
 type CowSpawner struct {
 	scene *game.Scene
 
-	bodyDef  *physics.BodyDefinition
-	modelDef *game.ModelDefinition
-	burstDef *game.ModelDefinition
+	bodyDefs    map[string]*physics.BodyDefinition
+	models      map[string]*game.ModelDefinition
+	effectPools map[string]*EffectPool
 }
 
-func (s *CowSpawner) SpawnCow(location dprec.Vec3) *Cow {
-	body := s.scene.Physics().CreateBody(physics.BodyInfo{
-		Name:       "Cow",
-		Definition: s.bodyDef,
-		Position:   location,
-		Rotation:   dprec.IdentityQuat(),
-	})
-	body.SetRotation(dprec.RotationQuat(dprec.Degrees(rand.Float64()*90), dprec.BasisYVec3()))
-	s.scene.Physics().CreateSingleBodyConstraint(body,
-		constraint.NewStaticPosition().SetPosition(location),
-	)
+// RegisterEffect prepares a pool of capacity instances of the model with
+// the specified name, so that it can be used with PlayEffect.
+func (s *CowSpawner) RegisterEffect(name string, capacity int, duration time.Duration) {
+	if pool, ok := s.effectPools[name]; ok && len(pool.effects) >= capacity {
+		return
+	}
+	s.effectPools[name] = NewEffectPool(s.scene, s.models[name], capacity, duration)
+}
+
+// PlayEffect plays the one-shot effect with the specified name. Effects that
+// were not registered in advance are registered with default settings.
+func (s *CowSpawner) PlayEffect(name string, position dprec.Vec3, rotation dprec.Quat, scale float64) {
+	if _, ok := s.effectPools[name]; !ok {
+		s.RegisterEffect(name, defaultEffectCapacity, defaultEffectDuration)
+	}
+	s.effectPools[name].Play(position, rotation, scale)
+}
+
+func (s *CowSpawner) Update(elapsedTime time.Duration) {
+	for _, pool := range s.effectPools {
+		pool.Update(elapsedTime)
+	}
+}
+
+func (s *CowSpawner) SpawnCow(location dprec.Vec3, archetype *data.CowArchetype, behaviour data.CowBehaviour) *Cow {
+	body, positionConstraint := s.createBody(location, archetype)
 	model := s.scene.CreateModel(game.ModelInfo{
-		Definition:        s.modelDef,
+		Definition:        s.models[archetype.Model],
 		Name:              "Cow",
 		Position:          location,
 		Rotation:          dprec.IdentityQuat(),
-		Scale:             dprec.NewVec3(1.0, 1.0, 1.0),
+		Scale:             dprec.NewVec3(archetype.Scale, archetype.Scale, archetype.Scale),
 		IsDynamic:         true,
 		PrepareAnimations: true,
 	})
-	model.Root().SetSource(game.BodyNodeSource{
-		Body: body,
+
+	if _, ok := s.effectPools[archetype.PopEffect]; !ok {
+		s.RegisterEffect(archetype.PopEffect, defaultEffectCapacity, defaultEffectDuration)
+	}
+
+	cow := &Cow{
+		Archetype:          archetype,
+		Body:               body,
+		PositionConstraint: positionConstraint,
+		Model:              model,
+		Active:             true,
+	}
+
+	model.Root().SetSource(cowNodeSource{
+		cow: cow,
 	})
 
-	burstModel := s.scene.CreateModel(game.ModelInfo{
-		Definition:        s.burstDef,
-		Name:              "Burst",
-		Position:          dprec.NewVec3(0.0, -1000.0, 0.0),
-		Rotation:          dprec.IdentityQuat(),
-		Scale:             dprec.NewVec3(1.0, 1.0, 1.0),
-		IsDynamic:         true,
-		PrepareAnimations: true,
+	cow.Entity = s.scene.ECS().CreateEntity()
+	ecs.AttachComponent(cow.Entity, &CowComponent{
+		Cow:       cow,
+		Behaviour: behaviour,
+		Home:      location,
+		Heading:   body.Rotation(),
+		Target:    location,
+	})
+
+	return cow
+}
+
+// ResetCow puts the cow back where it was spawned and undoes any damage,
+// creating a new body for it if it had burst.
+func (s *CowSpawner) ResetCow(cow *Cow) {
+	var cowComp *CowComponent
+	ecs.FetchComponent(cow.Entity, &cowComp)
+	if cow.Active {
+		cow.Body.SetPosition(cowComp.Home)
+		cow.Body.SetRotation(cowComp.Heading)
+		cow.Body.SetVelocity(dprec.ZeroVec3())
+		cow.Body.SetAngularVelocity(dprec.ZeroVec3())
+		cow.PositionConstraint.SetPosition(cowComp.Home)
+	} else {
+		cow.Body, cow.PositionConstraint = s.createBody(cowComp.Home, cow.Archetype)
+		cow.Body.SetRotation(cowComp.Heading)
+	}
+	cow.Active = true
+	cow.Hits = 0
+	cow.WobbleDuration = 0
+	cow.WobbleStrength = 0.0
+
+	cowComp.State = CowStateIdle
+	cowComp.Target = cowComp.Home
+	cowComp.Pause = 0.0
+	cowComp.Ground = dprec.NewVec3(cowComp.Home.X, cowComp.Home.Y-cowComp.GroundOffset, cowComp.Home.Z)
+}
+
+// ResetEffects stops all pop effects that are currently playing.
+func (s *CowSpawner) ResetEffects() {
+	for _, pool := range s.effectPools {
+		pool.Reset()
+	}
+}
+
+func (s *CowSpawner) createBody(location dprec.Vec3, archetype *data.CowArchetype) (physics.Body, *constraint.StaticPosition) {
+	body := s.scene.Physics().CreateBody(physics.BodyInfo{
+		Name:       "Cow",
+		Definition: s.bodyDefinition(archetype),
+		Position:   location,
+		Rotation:   dprec.IdentityQuat(),
 	})
+	body.SetRotation(dprec.RotationQuat(dprec.Degrees(rand.Float64()*90), dprec.BasisYVec3()))
+	positionConstraint := constraint.NewStaticPosition().SetPosition(location)
+	s.scene.Physics().CreateSingleBodyConstraint(body, positionConstraint)
+	return body, positionConstraint
+}
 
-	return &Cow{
-		Body:       body,
-		Model:      model,
-		BurstModel: burstModel,
-		Active:     true,
+func (s *CowSpawner) bodyDefinition(archetype *data.CowArchetype) *physics.BodyDefinition {
+	if bodyDef, ok := s.bodyDefs[archetype.Name]; ok {
+		return bodyDef
 	}
+	bodyDef := s.scene.Physics().Engine().CreateBodyDefinition(physics.BodyDefinitionInfo{
+		Mass:                   100.0,
+		MomentOfInertia:        physics.SymmetricMomentOfInertia(100.0 / 2.0),
+		FrictionCoefficient:    1.0,
+		RestitutionCoefficient: 1.0,
+		DragFactor:             0.0,
+		AngularDragFactor:      0.0,
+		CollisionSpheres: []collision.Sphere{
+			collision.NewSphere(dprec.ZeroVec3(), archetype.CollisionRadius),
+		},
+	})
+	s.bodyDefs[archetype.Name] = bodyDef
+	return bodyDef
 }
 
 type Cow struct {
-	Body          physics.Body
-	Model         *game.Model
-	BurstModel    *game.Model
-	Active        bool
-	BurstDuration time.Duration
+	Entity             *ecs.Entity
+	Archetype          *data.CowArchetype
+	Body               physics.Body
+	PositionConstraint *constraint.StaticPosition
+	Model              *game.Model
+	Active             bool
+	Hits               int
+	WobbleDuration     time.Duration
+	WobbleStrength     float64
+	LastWobbleSound    time.Time
+
+	// bouncePlayback is reused by every wobble, since playbacks stay in
+	// the scene until they are deleted.
+	bouncePlayback *game.Playback
+
+	// PoppedBy is the index of the player that popped the cow. It is only
+	// meaningful when the cow is not Active.
+	PoppedBy int
 }
 
-func (c *Cow) Burst(scene *game.Scene) {
+// Hit registers a ball contact with the specified impact speed and returns
+// how the cow reacts to it.
+func (c *Cow) Hit(impactSpeed float64) CowHitResult {
+	if c.Archetype.MinImpactSpeed > 0.0 && impactSpeed >= c.Archetype.MinImpactSpeed {
+		return CowHitPop
+	}
+	if impactSpeed < c.Archetype.PopImpactSpeed {
+		return CowHitWobble
+	}
+	c.Hits++
+	if c.Hits < c.Archetype.Hits {
+		return CowHitDamage
+	}
+	return CowHitPop
+}
+
+// ImpactStrength returns the specified impact speed relative to the speed
+// that is needed to register a hit, clamped to [0.0, 2.0].
+func (c *Cow) ImpactStrength(impactSpeed float64) float64 {
+	if c.Archetype.PopImpactSpeed <= 0.0 {
+		return 2.0
+	}
+	return dprec.Clamp(impactSpeed/c.Archetype.PopImpactSpeed, 0.0, 2.0)
+}
+
+func (c *Cow) Wobble(scene *game.Scene, strength float64) {
+	c.WobbleDuration = cowWobbleDuration
+	c.WobbleStrength = dprec.Clamp(strength, 0.0, 1.0)
+	if c.bouncePlayback == nil {
+		animation := c.Model.FindAnimation("Bounce")
+		if animation == nil {
+			return
+		}
+		c.bouncePlayback = scene.PlayAnimation(animation)
+		return
+	}
+	c.bouncePlayback.Stop()
+	c.bouncePlayback.Play()
+}
+
+func (c *Cow) Position() dprec.Vec3 {
+	return c.PositionConstraint.Position()
+}
+
+func (c *Cow) MoveTo(position dprec.Vec3) {
+	delta := dprec.Vec3Diff(position, c.PositionConstraint.Position())
+	if delta.X*delta.X+delta.Z*delta.Z > 0.000001 {
+		yaw := dprec.Radians(math.Atan2(delta.X, delta.Z))
+		c.Body.SetRotation(dprec.RotationQuat(yaw, dprec.BasisYVec3()))
+	}
+	c.PositionConstraint.SetPosition(position)
+}
+
+func (c *Cow) Burst() {
 	c.Active = false
 	c.Body.Delete()
-	c.BurstDuration = time.Second
-	c.BurstModel.Root().SetPosition(c.Model.Root().Position())
-	c.BurstModel.Root().SetRotation(c.Model.Root().Rotation())
-
-	for _, animation := range c.BurstModel.Animations() {
-		scene.PlayAnimation(animation)
+	if c.bouncePlayback != nil {
+		c.bouncePlayback.Delete()
+		c.bouncePlayback = nil
 	}
 }
 
 func (c *Cow) Update(elapsedTime time.Duration) {
-	c.BurstDuration -= elapsedTime
-	if c.BurstDuration < 0 {
-		c.BurstModel.Root().SetPosition(dprec.NewVec3(0.0, -1000.0, 0.0))
+	c.WobbleDuration = max(0, c.WobbleDuration-elapsedTime)
+}
+
+type cowNodeSource struct {
+	cow *Cow
+}
+
+func (s cowNodeSource) ApplyTo(node *hierarchy.Node) {
+	translation := s.cow.Body.IntermediatePosition()
+	rotation := s.cow.Body.IntermediateRotation()
+	scale := dprec.NewVec3(s.cow.Archetype.Scale, s.cow.Archetype.Scale, s.cow.Archetype.Scale)
+	if s.cow.WobbleDuration > 0 {
+		progress := 1.0 - s.cow.WobbleDuration.Seconds()/cowWobbleDuration.Seconds()
+		squash := cowWobbleAmount * s.cow.WobbleStrength * (1.0 - progress) * dprec.Sin(dprec.Degrees(progress*360.0*cowWobbleCycles))
+		scale.Y *= 1.0 - squash
+		scale.X *= 1.0 + squash/2.0
+		scale.Z *= 1.0 + squash/2.0
 	}
+	node.SetAbsoluteMatrix(dprec.TRSMat4(translation, rotation, scale))
 }
@@ -0,0 +1,289 @@
+package controller
+
+import (
+	"math/rand"
+
+	"github.com/mokiat/ggj2024/internal/game/data"
+	"github.com/mokiat/gomath/dprec"
+	"github.com/mokiat/lacking/game/ecs"
+)
+
+var (
+	CowComponentID = ecs.NewComponentTypeID()
+)
+
+const (
+	cowGrazeRadius   = 20.0
+	cowGrazeSpeed    = 2.0
+	cowMinGrazePause = 2.0
+	cowMaxGrazePause = 6.0
+
+	cowFleeStartDistance = 40.0
+	cowFleeStopDistance  = 70.0
+	cowFleeMaxAltitude   = 150.0
+	cowFleeSpeed         = 12.0
+	cowFleeMaxDistance   = 80.0
+
+	cowHerdRadius   = 60.0
+	cowHerdSpacing  = 18.0
+	cowHerdStrength = 0.5
+
+	// The terrain below a walking cow is probed again once the cow has
+	// moved this far, which is close enough for the slope not to show.
+	cowGroundProbeDistance = 0.5
+	cowGroundProbeHeight   = 50.0
+)
+
+type CowState int
+
+const (
+	CowStateIdle CowState = iota
+	CowStateWalking
+	CowStateFleeing
+)
+
+type CowComponent struct {
+	Cow       *Cow
+	Behaviour data.CowBehaviour
+	State     CowState
+	Home      dprec.Vec3
+	Heading   dprec.Quat
+	Target    dprec.Vec3
+	Pause     float64
+
+	// GroundOffset is the height of the cow above the terrain at Home,
+	// which is kept as the cow walks.
+	GroundOffset float64
+	// Ground is the last point on the terrain below the cow.
+	Ground dprec.Vec3
+}
+
+func (*CowComponent) TypeID() ecs.ComponentTypeID {
+	return CowComponentID
+}
+
+// NewCowSystem creates a system that moves the cows of the specified
+// scene. The terrain keeps walking cows on the ground and can be nil, in
+// which case cows walk at the height where they were spawned.
+func NewCowSystem(ecsScene *ecs.Scene, terrain *data.Terrain, airplanes ...*Airplane) *CowSystem {
+	system := &CowSystem{
+		ecsScene:  ecsScene,
+		terrain:   terrain,
+		airplanes: airplanes,
+	}
+	system.measureGround()
+	return system
+}
+
+// CowSystem moves cows around according to their configured behaviour.
+type CowSystem struct {
+	ecsScene  *ecs.Scene
+	terrain   *data.Terrain
+	airplanes []*Airplane
+
+	herd []dprec.Vec3
+}
+
+// measureGround records how high each cow is placed above the terrain.
+func (s *CowSystem) measureGround() {
+	result := s.ecsScene.Find(ecs.Having(CowComponentID))
+	defer result.Close()
+
+	var entity *ecs.Entity
+	for result.FetchNext(&entity) {
+		var cowComp *CowComponent
+		ecs.FetchComponent(entity, &cowComp)
+		cowComp.Ground = cowComp.Home
+		if ground, ok := s.groundBelow(cowComp.Home); ok {
+			cowComp.Ground = ground
+			cowComp.GroundOffset = cowComp.Home.Y - ground.Y
+		}
+	}
+}
+
+// groundBelow returns the point on the terrain that is right below or
+// above the specified position.
+func (s *CowSystem) groundBelow(position dprec.Vec3) (dprec.Vec3, bool) {
+	if s.terrain == nil {
+		return dprec.ZeroVec3(), false
+	}
+	return s.terrain.RayCast(
+		dprec.NewVec3(position.X, position.Y+cowGroundProbeHeight, position.Z),
+		dprec.NewVec3(position.X, position.Y-cowGroundProbeHeight, position.Z),
+	)
+}
+
+func (s *CowSystem) Update(elapsedSeconds float64) {
+	s.collectHerd()
+
+	result := s.ecsScene.Find(ecs.Having(CowComponentID))
+	defer result.Close()
+
+	var entity *ecs.Entity
+	for result.FetchNext(&entity) {
+		var cowComp *CowComponent
+		ecs.FetchComponent(entity, &cowComp)
+		if !cowComp.Cow.Active {
+			continue
+		}
+		switch cowComp.Behaviour {
+		case data.CowBehaviourGraze:
+			s.updateGrazing(elapsedSeconds, cowComp)
+		case data.CowBehaviourFlee, data.CowBehaviourHerd:
+			if !s.updateFleeing(elapsedSeconds, cowComp) {
+				s.updateGrazing(elapsedSeconds, cowComp)
+			}
+		}
+	}
+}
+
+func (s *CowSystem) collectHerd() {
+	result := s.ecsScene.Find(ecs.Having(CowComponentID))
+	defer result.Close()
+
+	s.herd = s.herd[:0]
+	var entity *ecs.Entity
+	for result.FetchNext(&entity) {
+		var cowComp *CowComponent
+		ecs.FetchComponent(entity, &cowComp)
+		if cowComp.Cow.Active && cowComp.Behaviour == data.CowBehaviourHerd {
+			s.herd = append(s.herd, cowComp.Cow.Position())
+		}
+	}
+}
+
+func (s *CowSystem) updateGrazing(elapsedSeconds float64, cowComp *CowComponent) {
+	switch cowComp.State {
+	case CowStateIdle:
+		cowComp.Pause -= elapsedSeconds
+		if cowComp.Pause <= 0.0 {
+			cowComp.Target = s.nextTarget(cowComp)
+			cowComp.State = CowStateWalking
+		}
+	case CowStateWalking, CowStateFleeing:
+		if s.walkTowards(elapsedSeconds, cowComp, cowGrazeSpeed) {
+			cowComp.Pause = cowMinGrazePause + rand.Float64()*(cowMaxGrazePause-cowMinGrazePause)
+			cowComp.State = CowStateIdle
+		}
+	}
+}
+
+func (s *CowSystem) updateFleeing(elapsedSeconds float64, cowComp *CowComponent) bool {
+	position := cowComp.Cow.Position()
+	shadow := s.nearestAirplane(position)
+	altitude := shadow.Y - position.Y
+	shadow.Y = position.Y
+
+	distance := horizontalDistance(position, shadow)
+	threshold := cowFleeStartDistance
+	if cowComp.State == CowStateFleeing {
+		threshold = cowFleeStopDistance
+	}
+	if altitude > cowFleeMaxAltitude || distance > threshold {
+		if cowComp.State == CowStateFleeing {
+			cowComp.Target = s.nextTarget(cowComp)
+			cowComp.State = CowStateWalking
+		}
+		return false
+	}
+
+	direction := dprec.Vec3Diff(position, shadow)
+	if direction.Length() < 0.001 {
+		direction = dprec.Vec3Diff(position, cowComp.Home)
+	}
+	if direction.Length() < 0.001 {
+		direction = dprec.BasisXVec3()
+	}
+	target := dprec.Vec3Sum(position, dprec.ResizedVec3(direction, cowFleeSpeed))
+	if offset := dprec.Vec3Diff(target, cowComp.Home); offset.Length() > cowFleeMaxDistance {
+		target = dprec.Vec3Sum(cowComp.Home, dprec.ResizedVec3(offset, cowFleeMaxDistance))
+	}
+	cowComp.Target = target
+	cowComp.State = CowStateFleeing
+	s.walkTowards(elapsedSeconds, cowComp, cowFleeSpeed)
+	return true
+}
+
+func (s *CowSystem) walkTowards(elapsedSeconds float64, cowComp *CowComponent, speed float64) bool {
+	position := cowComp.Cow.Position()
+	delta := dprec.Vec3Diff(cowComp.Target, position)
+	delta.Y = 0.0
+	step := speed * elapsedSeconds
+	if delta.Length() <= step {
+		cowComp.Cow.MoveTo(s.onGround(cowComp, dprec.NewVec3(cowComp.Target.X, position.Y, cowComp.Target.Z)))
+		return true
+	}
+	cowComp.Cow.MoveTo(s.onGround(cowComp, dprec.Vec3Sum(position, dprec.ResizedVec3(delta, step))))
+	return false
+}
+
+// onGround returns the specified position with its height adjusted to
+// follow the terrain.
+func (s *CowSystem) onGround(cowComp *CowComponent, position dprec.Vec3) dprec.Vec3 {
+	if horizontalDistance(position, cowComp.Ground) >= cowGroundProbeDistance {
+		if ground, ok := s.groundBelow(position); ok {
+			cowComp.Ground = ground
+		}
+	}
+	if s.terrain != nil {
+		position.Y = cowComp.Ground.Y + cowComp.GroundOffset
+	}
+	return position
+}
+
+func (s *CowSystem) nextTarget(cowComp *CowComponent) dprec.Vec3 {
+	target := s.grazeTarget(cowComp.Home)
+	if cowComp.Behaviour != data.CowBehaviourHerd {
+		return target
+	}
+
+	position := cowComp.Cow.Position()
+	var (
+		center dprec.Vec3
+		count  int
+	)
+	for _, other := range s.herd {
+		if horizontalDistance(position, other) < cowHerdRadius {
+			center = dprec.Vec3Sum(center, other)
+			count++
+		}
+	}
+	if count < 2 { // the cow itself is always part of the herd
+		return target
+	}
+	center = dprec.Vec3Quot(center, float64(count))
+
+	offset := dprec.Vec3Diff(position, center)
+	offset.Y = 0.0
+	if offset.Length() > 0.001 {
+		center = dprec.Vec3Sum(center, dprec.ResizedVec3(offset, cowHerdSpacing))
+	}
+	return dprec.Vec3Lerp(target, center, cowHerdStrength)
+}
+
+func (s *CowSystem) grazeTarget(home dprec.Vec3) dprec.Vec3 {
+	angle := dprec.Degrees(rand.Float64() * 360.0)
+	distance := rand.Float64() * cowGrazeRadius
+	return dprec.NewVec3(
+		home.X+dprec.Cos(angle)*distance,
+		home.Y,
+		home.Z+dprec.Sin(angle)*distance,
+	)
+}
+
+func horizontalDistance(a, b dprec.Vec3) float64 {
+	return dprec.NewVec2(a.X-b.X, a.Z-b.Z).Length()
+}
+
+// nearestAirplane returns the position of the airplane that is closest to
+// the specified position on the ground.
+func (s *CowSystem) nearestAirplane(position dprec.Vec3) dprec.Vec3 {
+	result := s.airplanes[0].Body.Position()
+	for _, airplane := range s.airplanes[1:] {
+		candidate := airplane.Body.Position()
+		if horizontalDistance(position, candidate) < horizontalDistance(position, result) {
+			result = candidate
+		}
+	}
+	return result
+}
@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"github.com/mokiat/lacking/app"
+	"github.com/mokiat/lacking/debug/log"
+)
+
+const (
+	DeviceEventConnected DeviceEventKind = iota
+	DeviceEventDisconnected
+	DeviceEventPressed
+)
+
+// DeviceEventKind indicates what happened to a gamepad.
+type DeviceEventKind int
+
+// DeviceEvent describes a change to one of the gamepad slots.
+type DeviceEvent struct {
+	Gamepad int
+	Kind    DeviceEventKind
+}
+
+func NewDeviceManager(window app.Window) *DeviceManager {
+	return &DeviceManager{
+		window: window,
+	}
+}
+
+// DeviceManager watches all gamepad slots of the window and reports when
+// gamepads are connected, disconnected or have a button pressed.
+type DeviceManager struct {
+	window    app.Window
+	connected [4]bool
+	pressed   [4]bool
+}
+
+// Connected returns whether a supported gamepad is connected at the
+// specified slot.
+func (m *DeviceManager) Connected(index int) bool {
+	return m.connected[index]
+}
+
+// Gamepad returns the gamepad at the specified slot.
+func (m *DeviceManager) Gamepad(index int) app.Gamepad {
+	return m.window.Gamepads()[index]
+}
+
+// Poll compares the gamepads with what they were during the previous
+// call and returns the changes. A press is reported only once, when any
+// button goes down while all were released.
+func (m *DeviceManager) Poll() []DeviceEvent {
+	var events []DeviceEvent
+	for i, gamepad := range m.window.Gamepads() {
+		connected := gamepad.Connected() && gamepad.Supported()
+		if connected != m.connected[i] {
+			m.connected[i] = connected
+			kind := DeviceEventDisconnected
+			if connected {
+				kind = DeviceEventConnected
+			}
+			events = append(events, DeviceEvent{
+				Gamepad: i,
+				Kind:    kind,
+			})
+		}
+		pressed := connected && isAnyButtonPressed(gamepad)
+		if pressed && !m.pressed[i] {
+			events = append(events, DeviceEvent{
+				Gamepad: i,
+				Kind:    DeviceEventPressed,
+			})
+		}
+		m.pressed[i] = pressed
+	}
+	return events
+}
+
+func isAnyButtonPressed(gamepad app.Gamepad) bool {
+	return gamepad.ActionUpButton() ||
+		gamepad.ActionDownButton() ||
+		gamepad.ActionLeftButton() ||
+		gamepad.ActionRightButton() ||
+		gamepad.ForwardButton() ||
+		gamepad.BackButton() ||
+		gamepad.LeftBumper() ||
+		gamepad.RightBumper()
+}
+
+// OnDisconnect registers a callback that is invoked when the gamepad of a
+// local player is disconnected during an offline game. The game should be
+// paused until AwaitingDevice returns false.
+func (c *PlayController) OnDisconnect(callback func(player *Player)) {
+	c.onDisconnect = callback
+}
+
+// AwaitingDevice returns whether any local player lost their gamepad and
+// has not picked another device yet.
+func (c *PlayController) AwaitingDevice() bool {
+	for _, player := range c.players {
+		if player.awaitingDevice {
+			return true
+		}
+	}
+	return false
+}
+
+// ContinueWithKeyboard makes all players that lost their gamepad continue
+// with the keyboard. Players that could not fall back to the keyboard,
+// since another player uses it, keep waiting for a gamepad.
+func (c *PlayController) ContinueWithKeyboard() {
+	for _, player := range c.players {
+		if player.HasKeyboard() {
+			player.awaitingDevice = false
+		}
+	}
+}
+
+// SelectGamepad makes the specified gamepad control the player, taking it
+// away from any other player.
+func (c *PlayController) SelectGamepad(player *Player, index int) {
+	if !c.devices.Connected(index) {
+		return
+	}
+	if owner := c.gamepadOwner(index); owner != nil && owner != player {
+		owner.useKeyboard()
+	}
+	player.useGamepad(index, c.devices.Gamepad(index))
+}
+
+// UpdateDevices applies gamepad changes to the players. It is called on
+// every update, but needs to be called by the UI as well while the game
+// is paused, since updates are not running then.
+func (c *PlayController) UpdateDevices() {
+	for _, event := range c.devices.Poll() {
+		switch event.Kind {
+		case DeviceEventConnected:
+			c.onGamepadConnected(event.Gamepad)
+		case DeviceEventDisconnected:
+			c.onGamepadDisconnected(event.Gamepad)
+		case DeviceEventPressed:
+			c.onGamepadPressed(event.Gamepad)
+		}
+	}
+}
+
+func (c *PlayController) onGamepadConnected(index int) {
+	if c.gamepadOwner(index) != nil {
+		return
+	}
+	// A gamepad that comes back goes to whoever had it, then to players
+	// that have nothing to fly with and finally to a single player who
+	// flies with the keyboard until a gamepad shows up.
+	candidates := []func(player *Player) bool{
+		func(player *Player) bool {
+			return player.Input == PlayerInputGamepad(index) && player.gamepadController == nil
+		},
+		func(player *Player) bool {
+			return player.awaitingDevice || (player.gamepadController == nil && player.keyboardController == nil)
+		},
+		func(player *Player) bool {
+			return player.Input == PlayerInputAuto && player.gamepadController == nil
+		},
+	}
+	for _, candidate := range candidates {
+		for _, player := range c.LocalPlayers() {
+			if candidate(player) {
+				log.Info("Gamepad %d connected to player %d", index+1, player.Index+1)
+				player.useGamepad(index, c.devices.Gamepad(index))
+				return
+			}
+		}
+	}
+}
+
+func (c *PlayController) onGamepadDisconnected(index int) {
+	player := c.gamepadOwner(index)
+	if player == nil {
+		return
+	}
+	log.Info("Gamepad %d of player %d disconnected", index+1, player.Index+1)
+	player.gamepadController = nil
+	player.feedback.SetHaptics(NopHaptics{})
+	if player.Input == PlayerInputAuto || !c.keyboardInUse() {
+		player.useKeyboard()
+	} else {
+		player.keyboardController = nil
+	}
+	// A network game cannot be paused, so the player just continues with
+	// the keyboard, if possible.
+	if c.Online() {
+		return
+	}
+	player.awaitingDevice = true
+	if c.onDisconnect != nil {
+		c.onDisconnect(player)
+	}
+}
+
+func (c *PlayController) onGamepadPressed(index int) {
+	if c.gamepadOwner(index) != nil {
+		return
+	}
+	for _, player := range c.LocalPlayers() {
+		if player.awaitingDevice {
+			c.SelectGamepad(player, index)
+			return
+		}
+	}
+	// A single player can switch to another gamepad by pressing any of its
+	// buttons.
+	if players := c.LocalPlayers(); len(players) == 1 {
+		log.Info("Player switched to gamepad %d", index+1)
+		c.SelectGamepad(players[0], index)
+	}
+}
+
+func (c *PlayController) gamepadOwner(index int) *Player {
+	for _, player := range c.players {
+		if player.gamepadController != nil && player.gamepad == index {
+			return player
+		}
+	}
+	return nil
+}
+
+func (c *PlayController) keyboardInUse() bool {
+	for _, player := range c.players {
+		if player.keyboardController != nil && player.gamepadController == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/mokiat/gomath/dprec"
+	"github.com/mokiat/lacking/game"
+)
+
+const (
+	defaultEffectCapacity = 8
+	defaultEffectDuration = time.Second
+)
+
+var effectParkingPosition = dprec.NewVec3(0.0, -1000.0, 0.0)
+
+// NewEffectPool creates a pool of capacity model instances that are reused
+// to play a short one-shot effect. Idle instances are parked below the
+// level.
+func NewEffectPool(scene *game.Scene, definition *game.ModelDefinition, capacity int, duration time.Duration) *EffectPool {
+	effects := make([]effect, capacity)
+	for i := range effects {
+		model := scene.CreateModel(game.ModelInfo{
+			Definition:        definition,
+			Name:              "Effect",
+			Position:          effectParkingPosition,
+			Rotation:          dprec.IdentityQuat(),
+			Scale:             dprec.NewVec3(1.0, 1.0, 1.0),
+			IsDynamic:         true,
+			PrepareAnimations: true,
+		})
+		playbacks := make([]*game.Playback, len(model.Animations()))
+		for j, animation := range model.Animations() {
+			playbacks[j] = scene.PlayAnimation(animation)
+			playbacks[j].Stop()
+		}
+		effects[i] = effect{
+			model:     model,
+			playbacks: playbacks,
+		}
+	}
+	return &EffectPool{
+		effects:  effects,
+		duration: duration,
+	}
+}
+
+type EffectPool struct {
+	effects  []effect
+	duration time.Duration
+}
+
+// Play shows the effect at the specified location. If all instances are
+// busy, the one that is closest to finishing is restarted.
+func (p *EffectPool) Play(position dprec.Vec3, rotation dprec.Quat, scale float64) {
+	if len(p.effects) == 0 {
+		return
+	}
+	target := &p.effects[0]
+	for i := range p.effects {
+		candidate := &p.effects[i]
+		if candidate.remaining <= 0 {
+			target = candidate
+			break
+		}
+		if candidate.remaining < target.remaining {
+			target = candidate
+		}
+	}
+
+	root := target.model.Root()
+	root.SetPosition(position)
+	root.SetRotation(rotation)
+	root.SetScale(dprec.NewVec3(scale, scale, scale))
+	for _, playback := range target.playbacks {
+		playback.Stop()
+		playback.Play()
+	}
+	target.remaining = p.duration
+}
+
+func (p *EffectPool) Update(elapsedTime time.Duration) {
+	for i := range p.effects {
+		effect := &p.effects[i]
+		if effect.remaining <= 0 {
+			continue
+		}
+		effect.remaining -= elapsedTime
+		if effect.remaining <= 0 {
+			effect.model.Root().SetPosition(effectParkingPosition)
+		}
+	}
+}
+
+// Reset stops all effects that are currently playing.
+func (p *EffectPool) Reset() {
+	for i := range p.effects {
+		effect := &p.effects[i]
+		for _, playback := range effect.playbacks {
+			playback.Stop()
+		}
+		effect.remaining = 0
+		effect.model.Root().SetPosition(effectParkingPosition)
+	}
+}
+
+type effect struct {
+	model     *game.Model
+	playbacks []*game.Playback
+	remaining time.Duration
+}
@@ -0,0 +1,60 @@
+package controller
+
+import "time"
+
+// Topic is a typed subscription list on an event bus.
+type Topic[T any] struct {
+	callbacks []func(event T)
+}
+
+func (t *Topic[T]) Subscribe(callback func(event T)) {
+	t.callbacks = append(t.callbacks, callback)
+}
+
+func (t *Topic[T]) Publish(event T) {
+	for _, callback := range t.callbacks {
+		callback(event)
+	}
+}
+
+// CowPopped is published when a player pops a cow.
+type CowPopped struct {
+	Player *Player
+	Cow    *Cow
+
+	// Time is the game time at which the cow was popped.
+	Time time.Duration
+
+	// Inverted indicates that the airplane was flying upside down.
+	Inverted bool
+}
+
+// CowRubbed is published when the airplane of a player starts rubbing a
+// cow.
+type CowRubbed struct {
+	Player *Player
+	Cow    *Cow
+}
+
+// GameFinished is published once, when the game is won or lost.
+type GameFinished struct {
+	Outcome GameOutcome
+	Elapsed time.Duration
+
+	// TimeLeft is the time that remained on the countdown of the game
+	// mode. It is only meaningful when Countdown is true.
+	TimeLeft  time.Duration
+	Countdown bool
+
+	// Winners are the players that won the game. All players win
+	// together, unless the game mode picks a single winner.
+	Winners []*Player
+}
+
+// GameEventBus distributes gameplay events to interested parties, such as
+// achievements.
+type GameEventBus struct {
+	CowPopped    Topic[CowPopped]
+	CowRubbed    Topic[CowRubbed]
+	GameFinished Topic[GameFinished]
+}
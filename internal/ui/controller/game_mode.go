@@ -0,0 +1,481 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/mokiat/ggj2024/internal/game/data"
+)
+
+const (
+	GameModeClassic    = "classic"
+	GameModeTimeAttack = "time-attack"
+	GameModeEndless    = "endless"
+	GameModeZen        = "zen"
+	GameModeDaily      = "daily"
+	GameModeVersus     = "versus"
+	GameModeCoop       = "co-op"
+)
+
+const (
+	GameOutcomeNone GameOutcome = iota
+	GameOutcomeVictory
+	GameOutcomeDefeat
+)
+
+// GameOutcome indicates whether a game has finished and how.
+type GameOutcome int
+
+// GameStatus is a summary of the game that the GameMode rules work with.
+type GameStatus struct {
+	// Elapsed is the time spent playing, not including penalties.
+	Elapsed time.Duration
+
+	// Penalty is the time that all players together have lost to
+	// respawns and decoys.
+	Penalty time.Duration
+
+	// Score is the number of points collected by popping cows.
+	Score int
+
+	// Pops is the number of cows that have been popped.
+	Pops int
+
+	// ScoringPops is the part of Pops that was worth points, which leaves
+	// out decoys.
+	ScoringPops int
+
+	// MaxScore is the number of points that all cows in the level are
+	// worth together.
+	MaxScore int
+
+	// Players holds the share of each player in Score and Pops.
+	Players []PlayerStatus
+}
+
+// PlayerStatus is the part of the GameStatus that a single player has
+// achieved.
+type PlayerStatus struct {
+	Score   int
+	Pops    int
+	Penalty time.Duration
+}
+
+// TimeTaken returns the time spent playing, including the penalties of
+// all players, since the players of a team share the clock.
+func (s GameStatus) TimeTaken() time.Duration {
+	return s.Elapsed + s.Penalty
+}
+
+// HUDConfig specifies which parts of the HUD a GameMode needs.
+type HUDConfig struct {
+	ShowTimer       bool
+	ShowCowsCounter bool
+}
+
+// GameMode owns the rules that decide when a game is won or lost.
+type GameMode interface {
+	HUD() HUDConfig
+
+	// RespawnCows returns whether popped cows should come back after a
+	// while.
+	RespawnCows() bool
+
+	// Timer returns the time that is displayed on the HUD.
+	Timer(status GameStatus) time.Duration
+
+	// Counter returns the number that is displayed on the cows counter.
+	Counter(status GameStatus) int
+
+	Outcome(status GameStatus) GameOutcome
+}
+
+// CountdownGameMode is a GameMode that has to be finished before its timer
+// runs out.
+type CountdownGameMode interface {
+	GameMode
+	TimeLeft(status GameStatus) time.Duration
+}
+
+// TargetGameMode is a GameMode that is won by reaching a score.
+type TargetGameMode interface {
+	GameMode
+	RequiredScore(status GameStatus) int
+}
+
+// SeededGameMode is a GameMode that plays a generated layout of the level
+// instead of the authored one.
+type SeededGameMode interface {
+	GameMode
+	Challenge() *data.Challenge
+}
+
+// MultiplayerGameMode is a GameMode that is played by several players on
+// a split screen.
+type MultiplayerGameMode interface {
+	GameMode
+	Players() int
+
+	// PlayerCounter returns the number that is displayed on the cows
+	// counter of the specified player.
+	PlayerCounter(status GameStatus, player int) int
+
+	// Winner returns the index of the player that won or -1 if the
+	// players share the result.
+	Winner(status GameStatus) int
+}
+
+// BoundsGameMode is a GameMode that overrides what the level does with
+// players that stay outside of its bounds for too long.
+type BoundsGameMode interface {
+	GameMode
+	OutOfBoundsAction(action data.OutOfBoundsAction) data.OutOfBoundsAction
+}
+
+// GameModeInfo describes a GameMode for the purpose of selecting it.
+type GameModeInfo struct {
+	Name        string
+	Title       string
+	Description string
+	Create      func() GameMode
+}
+
+var GameModes = []GameModeInfo{
+	{
+		Name:        GameModeClassic,
+		Title:       "Classic",
+		Description: "Pop 10 cows in two minutes.",
+		Create: func() GameMode {
+			return &ClassicGameMode{
+				Target:    10,
+				TimeLimit: 120 * time.Second,
+			}
+		},
+	},
+	{
+		Name:        GameModeTimeAttack,
+		Title:       "Time Attack",
+		Description: "Pop every cow as fast as you can.",
+		Create: func() GameMode {
+			return &TimeAttackGameMode{}
+		},
+	},
+	{
+		Name:        GameModeEndless,
+		Title:       "Endless",
+		Description: "Cows keep coming back. Every pop buys you time.",
+		Create: func() GameMode {
+			return &EndlessGameMode{
+				TimeLimit: 60 * time.Second,
+				PopBonus:  10 * time.Second,
+			}
+		},
+	},
+	{
+		Name:        GameModeZen,
+		Title:       "Zen",
+		Description: "No clock, no pressure.",
+		Create: func() GameMode {
+			return &ZenGameMode{}
+		},
+	},
+	{
+		Name:        GameModeDaily,
+		Title:       "Daily Challenge",
+		Description: "Same cows, wind and payload for everyone. Changes every day.",
+		Create: func() GameMode {
+			return &DailyGameMode{
+				challenge: data.NewDailyChallenge(time.Now()),
+			}
+		},
+	},
+	{
+		Name:        GameModeVersus,
+		Title:       "Versus",
+		Description: "Two players, split screen. Most points in two minutes wins.",
+		Create: func() GameMode {
+			return &VersusGameMode{
+				PlayerCount: 2,
+				TimeLimit:   120 * time.Second,
+			}
+		},
+	},
+	{
+		Name:        GameModeCoop,
+		Title:       "Co-op",
+		Description: "Two players, split screen. Pop 15 cows together in two minutes.",
+		Create: func() GameMode {
+			return &CoopGameMode{
+				ClassicGameMode: ClassicGameMode{
+					Target:    15,
+					TimeLimit: 120 * time.Second,
+				},
+				PlayerCount: 2,
+			}
+		},
+	},
+}
+
+// NewGameMode creates the GameMode with the specified name, falling back
+// to the classic one.
+func NewGameMode(name string) GameMode {
+	for _, info := range GameModes {
+		if info.Name == name {
+			return info.Create()
+		}
+	}
+	return GameModes[0].Create()
+}
+
+// ClassicGameMode is won by collecting Target points within TimeLimit.
+type ClassicGameMode struct {
+	Target    int
+	TimeLimit time.Duration
+}
+
+func (m *ClassicGameMode) HUD() HUDConfig {
+	return HUDConfig{
+		ShowTimer:       true,
+		ShowCowsCounter: true,
+	}
+}
+
+func (m *ClassicGameMode) RespawnCows() bool {
+	return false
+}
+
+func (m *ClassicGameMode) Timer(status GameStatus) time.Duration {
+	return m.TimeLeft(status)
+}
+
+func (m *ClassicGameMode) TimeLeft(status GameStatus) time.Duration {
+	return max(0, m.TimeLimit-status.TimeTaken())
+}
+
+func (m *ClassicGameMode) RequiredScore(status GameStatus) int {
+	return m.Target
+}
+
+func (m *ClassicGameMode) Counter(status GameStatus) int {
+	return max(0, m.Target-status.Score)
+}
+
+func (m *ClassicGameMode) Outcome(status GameStatus) GameOutcome {
+	if status.Score >= m.Target {
+		return GameOutcomeVictory
+	}
+	if status.TimeTaken() > m.TimeLimit {
+		return GameOutcomeDefeat
+	}
+	return GameOutcomeNone
+}
+
+// TimeAttackGameMode is won by collecting all points in the level. There
+// is no time limit and the timer shows the time taken.
+type TimeAttackGameMode struct{}
+
+func (m *TimeAttackGameMode) HUD() HUDConfig {
+	return HUDConfig{
+		ShowTimer:       true,
+		ShowCowsCounter: true,
+	}
+}
+
+func (m *TimeAttackGameMode) RespawnCows() bool {
+	return false
+}
+
+func (m *TimeAttackGameMode) Timer(status GameStatus) time.Duration {
+	return status.TimeTaken()
+}
+
+func (m *TimeAttackGameMode) RequiredScore(status GameStatus) int {
+	return status.MaxScore
+}
+
+func (m *TimeAttackGameMode) Counter(status GameStatus) int {
+	return max(0, status.MaxScore-status.Score)
+}
+
+func (m *TimeAttackGameMode) Outcome(status GameStatus) GameOutcome {
+	if status.MaxScore > 0 && status.Score >= status.MaxScore {
+		return GameOutcomeVictory
+	}
+	return GameOutcomeNone
+}
+
+// EndlessGameMode respawns popped cows and extends the time limit by
+// PopBonus with every pop that scores. It only ends when time runs out.
+type EndlessGameMode struct {
+	TimeLimit time.Duration
+	PopBonus  time.Duration
+}
+
+func (m *EndlessGameMode) HUD() HUDConfig {
+	return HUDConfig{
+		ShowTimer:       true,
+		ShowCowsCounter: true,
+	}
+}
+
+func (m *EndlessGameMode) RespawnCows() bool {
+	return true
+}
+
+func (m *EndlessGameMode) Timer(status GameStatus) time.Duration {
+	return max(0, m.deadline(status)-status.TimeTaken())
+}
+
+func (m *EndlessGameMode) Counter(status GameStatus) int {
+	return status.Score
+}
+
+func (m *EndlessGameMode) Outcome(status GameStatus) GameOutcome {
+	if status.TimeTaken() > m.deadline(status) {
+		return GameOutcomeDefeat
+	}
+	return GameOutcomeNone
+}
+
+func (m *EndlessGameMode) deadline(status GameStatus) time.Duration {
+	return m.TimeLimit + time.Duration(status.ScoringPops)*m.PopBonus
+}
+
+// ZenGameMode has no timer and cannot be lost. It is won once all cows
+// have been popped.
+type ZenGameMode struct{}
+
+func (m *ZenGameMode) HUD() HUDConfig {
+	return HUDConfig{
+		ShowTimer:       false,
+		ShowCowsCounter: true,
+	}
+}
+
+func (m *ZenGameMode) RespawnCows() bool {
+	return false
+}
+
+func (m *ZenGameMode) Timer(status GameStatus) time.Duration {
+	return 0
+}
+
+func (m *ZenGameMode) Counter(status GameStatus) int {
+	return max(0, status.MaxScore-status.Score)
+}
+
+func (m *ZenGameMode) OutOfBoundsAction(action data.OutOfBoundsAction) data.OutOfBoundsAction {
+	return data.OutOfBoundsRespawn
+}
+
+func (m *ZenGameMode) Outcome(status GameStatus) GameOutcome {
+	if status.MaxScore > 0 && status.Score >= status.MaxScore {
+		return GameOutcomeVictory
+	}
+	return GameOutcomeNone
+}
+
+// DailyGameMode is a TimeAttackGameMode on the layout of the daily
+// challenge.
+type DailyGameMode struct {
+	TimeAttackGameMode
+	challenge *data.Challenge
+}
+
+func (m *DailyGameMode) Challenge() *data.Challenge {
+	return m.challenge
+}
+
+// VersusGameMode lets players compete for the most points within
+// TimeLimit. The game also ends early once all cows have been popped.
+type VersusGameMode struct {
+	PlayerCount int
+	TimeLimit   time.Duration
+}
+
+func (m *VersusGameMode) HUD() HUDConfig {
+	return HUDConfig{
+		ShowTimer:       true,
+		ShowCowsCounter: true,
+	}
+}
+
+func (m *VersusGameMode) RespawnCows() bool {
+	return false
+}
+
+func (m *VersusGameMode) Timer(status GameStatus) time.Duration {
+	return m.TimeLeft(status)
+}
+
+func (m *VersusGameMode) TimeLeft(status GameStatus) time.Duration {
+	return max(0, m.TimeLimit-status.Elapsed)
+}
+
+func (m *VersusGameMode) Counter(status GameStatus) int {
+	return status.Score
+}
+
+func (m *VersusGameMode) Outcome(status GameStatus) GameOutcome {
+	if status.Elapsed > m.TimeLimit || (status.MaxScore > 0 && status.Score >= status.MaxScore) {
+		return GameOutcomeVictory
+	}
+	return GameOutcomeNone
+}
+
+// OutOfBoundsAction respawns players, since a defeat of one player would
+// end the game for the other one too.
+func (m *VersusGameMode) OutOfBoundsAction(action data.OutOfBoundsAction) data.OutOfBoundsAction {
+	return data.OutOfBoundsRespawn
+}
+
+func (m *VersusGameMode) Players() int {
+	return m.PlayerCount
+}
+
+func (m *VersusGameMode) PlayerCounter(status GameStatus, player int) int {
+	return status.Players[player].Score
+}
+
+func (m *VersusGameMode) Winner(status GameStatus) int {
+	winner := -1
+	tie := false
+	for i, player := range status.Players {
+		if winner < 0 {
+			winner = i
+			continue
+		}
+		// A tie in points goes to the player that lost less time to
+		// penalties.
+		best := status.Players[winner]
+		switch {
+		case player.Score > best.Score, player.Score == best.Score && player.Penalty < best.Penalty:
+			winner = i
+			tie = false
+		case player.Score == best.Score && player.Penalty == best.Penalty:
+			tie = true
+		}
+	}
+	if tie {
+		return -1
+	}
+	return winner
+}
+
+// CoopGameMode is a ClassicGameMode where the points of all players count
+// towards the same Target.
+type CoopGameMode struct {
+	ClassicGameMode
+	PlayerCount int
+}
+
+func (m *CoopGameMode) Players() int {
+	return m.PlayerCount
+}
+
+func (m *CoopGameMode) PlayerCounter(status GameStatus, player int) int {
+	return m.Counter(status)
+}
+
+func (m *CoopGameMode) Winner(status GameStatus) int {
+	return -1
+}
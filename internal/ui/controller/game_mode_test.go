@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEndlessGameModeBonus(t *testing.T) {
+	mode := &EndlessGameMode{
+		TimeLimit: 60 * time.Second,
+		PopBonus:  10 * time.Second,
+	}
+	testCases := []struct {
+		status GameStatus
+		timer  time.Duration
+	}{
+		{GameStatus{Elapsed: 20 * time.Second}, 40 * time.Second},
+		{GameStatus{Elapsed: 20 * time.Second, Pops: 2, ScoringPops: 2}, 60 * time.Second},
+		// A decoy costs its penalty and earns no bonus.
+		{GameStatus{Elapsed: 20 * time.Second, Penalty: 15 * time.Second, Pops: 3, ScoringPops: 2}, 45 * time.Second},
+	}
+	for _, testCase := range testCases {
+		if timer := mode.Timer(testCase.status); timer != testCase.timer {
+			t.Errorf("%+v: expected timer %v, got %v", testCase.status, testCase.timer, timer)
+		}
+	}
+
+	expired := GameStatus{Elapsed: 75 * time.Second, Pops: 2, ScoringPops: 1}
+	if outcome := mode.Outcome(expired); outcome != GameOutcomeDefeat {
+		t.Errorf("expected defeat once the time runs out, got %v", outcome)
+	}
+}
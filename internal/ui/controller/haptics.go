@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/mokiat/gomath/dprec"
+	"github.com/mokiat/lacking/app"
+)
+
+const (
+	HapticEventPop HapticEventKind = iota
+	HapticEventRubbing
+	HapticEventCrash
+	HapticEventStall
+)
+
+// HapticEventKind indicates what happened in the game that the player
+// should feel.
+type HapticEventKind int
+
+// HapticEvent is a gameplay event that produces tactile feedback. The
+// Strength is in the range [0.0, 1.0] and its meaning depends on the kind.
+type HapticEvent struct {
+	Kind     HapticEventKind
+	Strength float64
+}
+
+// Haptics is a device that can produce tactile feedback.
+type Haptics interface {
+	Pulse(intensity float64, duration time.Duration)
+}
+
+// NewGamepadHaptics returns Haptics that vibrate the specified gamepad.
+// Platforms without vibration support ignore the pulses.
+func NewGamepadHaptics(gamepad app.Gamepad) Haptics {
+	return &gamepadHaptics{
+		gamepad: gamepad,
+	}
+}
+
+type gamepadHaptics struct {
+	gamepad app.Gamepad
+}
+
+func (h *gamepadHaptics) Pulse(intensity float64, duration time.Duration) {
+	if h.gamepad.Connected() {
+		h.gamepad.Pulse(intensity, duration)
+	}
+}
+
+// NopHaptics are used when the player has no device that can vibrate.
+type NopHaptics struct{}
+
+func (NopHaptics) Pulse(intensity float64, duration time.Duration) {}
+
+// HapticPulse is a pulse that was requested from RecordingHaptics.
+type HapticPulse struct {
+	Intensity float64
+	Duration  time.Duration
+}
+
+// RecordingHaptics keep all pulses that were requested, so that the
+// feedback of gameplay events can be inspected without a device.
+type RecordingHaptics struct {
+	Pulses []HapticPulse
+}
+
+func (h *RecordingHaptics) Pulse(intensity float64, duration time.Duration) {
+	h.Pulses = append(h.Pulses, HapticPulse{
+		Intensity: intensity,
+		Duration:  duration,
+	})
+}
+
+// hapticPattern describes the pulse that an event kind produces. Events
+// that repeat, like rubbing or a stall, are limited by the cooldown, so
+// that they feel like a rattle and not a constant buzz.
+type hapticPattern struct {
+	minIntensity float64
+	maxIntensity float64
+	duration     time.Duration
+	cooldown     time.Duration
+}
+
+var hapticPatterns = map[HapticEventKind]hapticPattern{
+	HapticEventPop: {
+		minIntensity: 0.4,
+		maxIntensity: 0.8,
+		duration:     200 * time.Millisecond,
+	},
+	HapticEventRubbing: {
+		minIntensity: 0.3,
+		maxIntensity: 0.3,
+		duration:     120 * time.Millisecond,
+		cooldown:     300 * time.Millisecond,
+	},
+	HapticEventCrash: {
+		minIntensity: 1.0,
+		maxIntensity: 1.0,
+		duration:     500 * time.Millisecond,
+	},
+	HapticEventStall: {
+		minIntensity: 0.15,
+		maxIntensity: 0.6,
+		duration:     80 * time.Millisecond,
+		cooldown:     160 * time.Millisecond,
+	},
+}
+
+func NewHapticFeedback(haptics Haptics) *HapticFeedback {
+	return &HapticFeedback{
+		haptics:   haptics,
+		cooldowns: make(map[HapticEventKind]time.Duration),
+	}
+}
+
+// HapticFeedback turns gameplay events into pulses on a Haptics device.
+type HapticFeedback struct {
+	haptics   Haptics
+	cooldowns map[HapticEventKind]time.Duration
+}
+
+// SetHaptics changes the device that receives the pulses.
+func (f *HapticFeedback) SetHaptics(haptics Haptics) {
+	f.haptics = haptics
+}
+
+func (f *HapticFeedback) Handle(event HapticEvent) {
+	pattern, ok := hapticPatterns[event.Kind]
+	if !ok || f.cooldowns[event.Kind] > 0 {
+		return
+	}
+	strength := dprec.Clamp(event.Strength, 0.0, 1.0)
+	intensity := dprec.Mix(pattern.minIntensity, pattern.maxIntensity, strength)
+	f.haptics.Pulse(intensity, pattern.duration)
+	f.cooldowns[event.Kind] = pattern.cooldown
+}
+
+func (f *HapticFeedback) Update(elapsedTime time.Duration) {
+	for kind, remaining := range f.cooldowns {
+		f.cooldowns[kind] = max(0, remaining-elapsedTime)
+	}
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHapticFeedbackIntensity(t *testing.T) {
+	testCases := []struct {
+		event     HapticEvent
+		intensity float64
+	}{
+		{HapticEvent{Kind: HapticEventPop, Strength: 0.0}, 0.4},
+		{HapticEvent{Kind: HapticEventPop, Strength: 0.5}, 0.6},
+		{HapticEvent{Kind: HapticEventPop, Strength: 1.0}, 0.8},
+		{HapticEvent{Kind: HapticEventPop, Strength: 3.0}, 0.8},
+		{HapticEvent{Kind: HapticEventPop, Strength: -1.0}, 0.4},
+		{HapticEvent{Kind: HapticEventCrash, Strength: 0.2}, 1.0},
+		{HapticEvent{Kind: HapticEventStall, Strength: 1.0}, 0.6},
+	}
+	for _, testCase := range testCases {
+		haptics := &RecordingHaptics{}
+		NewHapticFeedback(haptics).Handle(testCase.event)
+		if len(haptics.Pulses) != 1 {
+			t.Fatalf("%+v: expected one pulse, got %d", testCase.event, len(haptics.Pulses))
+		}
+		pulse := haptics.Pulses[0]
+		if diff := pulse.Intensity - testCase.intensity; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("%+v: expected intensity %f, got %f", testCase.event, testCase.intensity, pulse.Intensity)
+		}
+		if pulse.Duration != hapticPatterns[testCase.event.Kind].duration {
+			t.Errorf("%+v: unexpected duration %v", testCase.event, pulse.Duration)
+		}
+	}
+}
+
+func TestHapticFeedbackCooldown(t *testing.T) {
+	haptics := &RecordingHaptics{}
+	feedback := NewHapticFeedback(haptics)
+	rubbing := HapticEvent{Kind: HapticEventRubbing, Strength: 1.0}
+
+	feedback.Handle(rubbing)
+	feedback.Handle(rubbing)
+	if len(haptics.Pulses) != 1 {
+		t.Fatalf("expected repeated event to be suppressed, got %d pulses", len(haptics.Pulses))
+	}
+
+	// Other kinds are not affected by the cooldown of rubbing.
+	feedback.Handle(HapticEvent{Kind: HapticEventPop, Strength: 1.0})
+	if len(haptics.Pulses) != 2 {
+		t.Fatalf("expected pop to pulse, got %d pulses", len(haptics.Pulses))
+	}
+
+	feedback.Update(200 * time.Millisecond)
+	feedback.Handle(rubbing)
+	if len(haptics.Pulses) != 2 {
+		t.Fatalf("expected event within cooldown to be suppressed, got %d pulses", len(haptics.Pulses))
+	}
+
+	feedback.Update(100 * time.Millisecond)
+	feedback.Handle(rubbing)
+	if len(haptics.Pulses) != 3 {
+		t.Fatalf("expected event after cooldown to pulse, got %d pulses", len(haptics.Pulses))
+	}
+}
+
+func TestHapticFeedbackDeviceSwap(t *testing.T) {
+	gamepad := &RecordingHaptics{}
+	feedback := NewHapticFeedback(gamepad)
+	feedback.Handle(HapticEvent{Kind: HapticEventPop, Strength: 1.0})
+
+	// The gamepad is disconnected and the player falls back to the
+	// keyboard, which cannot vibrate.
+	feedback.SetHaptics(NopHaptics{})
+	feedback.Handle(HapticEvent{Kind: HapticEventCrash, Strength: 1.0})
+	if len(gamepad.Pulses) != 1 {
+		t.Fatalf("expected disconnected gamepad to get no pulses, got %d", len(gamepad.Pulses))
+	}
+
+	// Another gamepad is picked up.
+	replacement := &RecordingHaptics{}
+	feedback.SetHaptics(replacement)
+	feedback.Handle(HapticEvent{Kind: HapticEventPop, Strength: 0.0})
+	if len(replacement.Pulses) != 1 || replacement.Pulses[0].Intensity != 0.4 {
+		t.Fatalf("expected replacement gamepad to get the pop, got %+v", replacement.Pulses)
+	}
+	if len(gamepad.Pulses) != 1 {
+		t.Fatalf("expected disconnected gamepad to get no more pulses, got %d", len(gamepad.Pulses))
+	}
+}
@@ -0,0 +1,296 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/mokiat/ggj2024/internal/game/netplay"
+	"github.com/mokiat/gomath/dprec"
+	"github.com/mokiat/lacking/audio"
+	"github.com/mokiat/lacking/debug/log"
+	"github.com/mokiat/lacking/game/physics"
+)
+
+const (
+	snapshotInterval   = 50 * time.Millisecond
+	interpolationDelay = 2 * snapshotInterval
+
+	// correctionRate is the portion of the prediction error of the local
+	// airplane that is removed per second.
+	correctionRate = 8.0
+
+	// maxSmoothCorrection is the prediction error above which the local
+	// airplane is moved to the server state right away.
+	maxSmoothCorrection = 20.0
+)
+
+// Host makes this game the server of a network game. Remote players are
+// simulated here from the inputs that the server receives. It needs to be
+// called before Start.
+func (c *PlayController) Host(server *netplay.Server) {
+	c.server = server
+}
+
+// Join makes this game a client of a network game. Only the local airplane
+// is simulated here and everything else follows the server. It needs to be
+// called before Start.
+func (c *PlayController) Join(client *netplay.Client) {
+	c.client = client
+}
+
+// Online returns whether the game is played over the network.
+func (c *PlayController) Online() bool {
+	return c.server != nil || c.client != nil
+}
+
+// LocalPlayers returns the players that are controlled on this machine.
+func (c *PlayController) LocalPlayers() []*Player {
+	var result []*Player
+	for _, player := range c.players {
+		if player.Input != PlayerInputRemote {
+			result = append(result, player)
+		}
+	}
+	return result
+}
+
+// networkSeed returns the seed that all peers of a network game share.
+func (c *PlayController) networkSeed() (int64, bool) {
+	switch {
+	case c.server != nil:
+		return c.server.Seed(), true
+	case c.client != nil:
+		return c.client.Seed(), true
+	default:
+		return 0, false
+	}
+}
+
+// networkInputs decides which players are controlled on this machine when
+// the game is played over the network.
+func (c *PlayController) networkInputs(count int) []PlayerInput {
+	local := int(netplay.HostPlayer)
+	if c.client != nil {
+		local = int(c.client.Player())
+	}
+	result := make([]PlayerInput, count)
+	for i := range result {
+		if i == local {
+			result[i] = PlayerInputAuto
+		} else {
+			result[i] = PlayerInputRemote
+		}
+	}
+	return result
+}
+
+func (c *PlayController) applyRemoteInputs() {
+	for _, player := range c.players {
+		if player.Input != PlayerInputRemote {
+			continue
+		}
+		inputs := c.server.TakeInputs(uint8(player.Index))
+		if len(inputs) == 0 {
+			continue
+		}
+		// Inputs hold the state of the controls, so only the latest matters.
+		input := inputs[len(inputs)-1]
+		player.Airplane.TargetThrust = dprec.Clamp(float64(input.Thrust), 0.0, maxThrust)
+		player.Airplane.AileronAngle = dprec.Angle(dprec.Clamp(float64(input.Aileron), -1.0, 1.0)) * maxAileronAngle
+		player.Airplane.ElevatorAngle = dprec.Angle(dprec.Clamp(float64(input.Elevator), -1.0, 1.0)) * maxElevatorAngle
+		player.Airplane.RudderAngle = dprec.Angle(dprec.Clamp(float64(input.Rudder), -1.0, 1.0)) * maxRudderAngle
+	}
+}
+
+func (c *PlayController) updateServer(elapsedTime time.Duration, finished bool) {
+	c.snapshotElapsed += elapsedTime
+	if c.snapshotElapsed < snapshotInterval && !finished {
+		return
+	}
+	c.snapshotElapsed = 0
+	c.snapshotTick++
+
+	snapshot := netplay.Snapshot{
+		Tick:     c.snapshotTick,
+		Time:     c.gameTime,
+		Finished: finished,
+		Winner:   int8(c.Winner()),
+		Players:  make([]netplay.PlayerState, len(c.players)),
+		Cows:     make([]netplay.CowState, len(c.cows)),
+	}
+	for i, player := range c.players {
+		snapshot.Players[i] = netplay.PlayerState{
+			Player:   uint8(player.Index),
+			Score:    int16(player.points),
+			Airplane: newNetworkBodyState(player.Airplane.Body),
+			Ball:     newNetworkBodyState(player.Ball.Body),
+		}
+	}
+	for i, cow := range c.cows {
+		snapshot.Cows[i] = netplay.CowState{
+			Position: cow.Position(),
+			Active:   cow.Active,
+			PoppedBy: uint8(cow.PoppedBy),
+		}
+	}
+	if err := c.server.Broadcast(snapshot); err != nil {
+		log.Warn("Failed to send snapshot: %v", err)
+	}
+}
+
+func (c *PlayController) sendLocalInput() {
+	for _, player := range c.LocalPlayers() {
+		sequence, err := c.client.SendInput(netplay.Input{
+			Thrust:   float32(player.Airplane.TargetThrust),
+			Aileron:  float32(player.Airplane.AileronAngle / maxAileronAngle),
+			Elevator: float32(player.Airplane.ElevatorAngle / maxElevatorAngle),
+			Rudder:   float32(player.Airplane.RudderAngle / maxRudderAngle),
+		})
+		if err != nil {
+			log.Warn("Failed to send input: %v", err)
+		}
+		c.inputSequence = sequence
+	}
+}
+
+// applySnapshot makes the client follow the state of the server. Remote
+// players and cows are shown slightly in the past, interpolated between
+// snapshots, while the local airplane is corrected towards where the
+// server says that it should be.
+func (c *PlayController) applySnapshot(elapsedTime time.Duration) {
+	latest, ok := c.client.Latest()
+	if !ok {
+		return
+	}
+	c.gameTime = latest.Time
+	for _, player := range c.players {
+		if state, ok := latest.Player(uint8(player.Index)); ok {
+			player.points = int(state.Score)
+		}
+	}
+
+	if latest.Tick != c.snapshotTick {
+		c.snapshotTick = latest.Tick
+		for _, player := range c.LocalPlayers() {
+			state, ok := latest.Player(uint8(player.Index))
+			if !ok {
+				continue
+			}
+			if correction, ok := c.predictor.Reconcile(latest.Ack, state.Airplane); ok {
+				c.correction = correction
+			}
+		}
+	}
+	c.applyCorrection(elapsedTime.Seconds())
+
+	interpolated, ok := c.client.Interpolated(interpolationDelay)
+	if !ok {
+		return
+	}
+	for _, player := range c.players {
+		if player.Input != PlayerInputRemote {
+			continue
+		}
+		if state, ok := interpolated.Player(uint8(player.Index)); ok {
+			applyNetworkBodyState(player.Airplane.Body, state.Airplane)
+			applyNetworkBodyState(player.Ball.Body, state.Ball)
+		}
+	}
+	for i, cow := range c.cows {
+		if i >= len(interpolated.Cows) {
+			break
+		}
+		state := interpolated.Cows[i]
+		switch {
+		case state.Active && cow.Active:
+			cow.MoveTo(state.Position)
+		case !state.Active && cow.Active:
+			c.popCow(cow, int(state.PoppedBy))
+		case state.Active && !cow.Active:
+			c.cowSpawner.ResetCow(cow)
+			c.collisions.Register(cow.Body, cow.Entity)
+		}
+	}
+}
+
+func (c *PlayController) applyCorrection(elapsedSeconds float64) {
+	for _, player := range c.LocalPlayers() {
+		body := player.Airplane.Body
+		amount := dprec.Min(correctionRate*elapsedSeconds, 1.0)
+		if c.correction.Position.Length() > maxSmoothCorrection {
+			amount = 1.0
+		}
+		if amount <= 0.0 {
+			return
+		}
+		position := dprec.Vec3Prod(c.correction.Position, amount)
+		velocity := dprec.Vec3Prod(c.correction.Velocity, amount)
+		rotation := dprec.QuatSlerp(dprec.IdentityQuat(), c.correction.Rotation, amount)
+		body.SetPosition(dprec.Vec3Sum(body.Position(), position))
+		body.SetVelocity(dprec.Vec3Sum(body.Velocity(), velocity))
+		body.SetRotation(dprec.UnitQuat(dprec.QuatProd(rotation, body.Rotation())))
+
+		c.correction.Position = dprec.Vec3Diff(c.correction.Position, position)
+		c.correction.Velocity = dprec.Vec3Diff(c.correction.Velocity, velocity)
+		c.correction.Rotation = dprec.QuatSlerp(c.correction.Rotation, dprec.IdentityQuat(), amount)
+	}
+}
+
+// recordPrediction stores where the local airplane ended up after the
+// last input, so that it can be compared with the server state later.
+func (c *PlayController) recordPrediction() {
+	for _, player := range c.LocalPlayers() {
+		c.predictor.Record(c.inputSequence, newNetworkBodyState(player.Airplane.Body))
+	}
+}
+
+// popCow bursts a cow that was popped by the server.
+func (c *PlayController) popCow(cow *Cow, poppedBy int) {
+	c.audioAPI.Play(c.playData.CowSounds[cow.Archetype.PopSound], audio.PlayInfo{
+		Gain: 1.0,
+	})
+	c.cowSpawner.PlayEffect(cow.Archetype.PopEffect, cow.Model.Root().Position(), cow.Model.Root().Rotation(), cow.Archetype.PopEffectScale)
+	c.collisions.Unregister(cow.Body)
+	cow.Burst()
+	cow.PoppedBy = poppedBy
+	for _, player := range c.players {
+		if player.Index == poppedBy {
+			c.events.CowPopped.Publish(CowPopped{
+				Player:   player,
+				Cow:      cow,
+				Time:     c.gameTime,
+				Inverted: player.Airplane.Inverted(),
+			})
+		}
+	}
+}
+
+func (c *PlayController) closeNetwork() {
+	if c.server != nil {
+		if err := c.server.Close(); err != nil {
+			log.Warn("Failed to close server: %v", err)
+		}
+		c.server = nil
+	}
+	if c.client != nil {
+		if err := c.client.Close(); err != nil {
+			log.Warn("Failed to close client: %v", err)
+		}
+		c.client = nil
+	}
+}
+
+func newNetworkBodyState(body physics.Body) netplay.BodyState {
+	return netplay.BodyState{
+		Position:        body.Position(),
+		Rotation:        body.Rotation(),
+		Velocity:        body.Velocity(),
+		AngularVelocity: body.AngularVelocity(),
+	}
+}
+
+func applyNetworkBodyState(body physics.Body, state netplay.BodyState) {
+	body.SetPosition(state.Position)
+	body.SetRotation(dprec.UnitQuat(state.Rotation))
+	body.SetVelocity(state.Velocity)
+	body.SetAngularVelocity(state.AngularVelocity)
+}
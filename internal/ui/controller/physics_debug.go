@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"github.com/mokiat/gomath/dprec"
+	"github.com/mokiat/lacking/game/graphics"
+	"github.com/mokiat/lacking/game/physics"
+	"github.com/mokiat/lacking/game/physics/collision"
+)
+
+const (
+	debugSphereSegments = 24
+	debugNormalLength   = 2.0
+	debugForceScale     = 0.001 // meters per newton
+	debugVelocityScale  = 0.25  // meters per meter per second
+	debugMinLength      = 0.01
+)
+
+var (
+	debugCollisionColor = dprec.NewVec3(0.0, 1.0, 0.0)
+	debugNormalColor    = dprec.NewVec3(0.2, 0.4, 1.0)
+	debugLiftColor      = dprec.NewVec3(1.0, 1.0, 0.0)
+	debugDragColor      = dprec.NewVec3(1.0, 0.0, 0.0)
+	debugLinkColor      = dprec.NewVec3(1.0, 0.0, 1.0)
+	debugVelocityColor  = dprec.NewVec3(0.0, 1.0, 1.0)
+)
+
+// debugSurface is an aerodynamic surface attached to a body. The physics
+// engine does not expose the aerodynamic shapes of a body, so they are kept
+// here in order to visualize the forces that act on them.
+type debugSurface struct {
+	body      physics.Body
+	transform physics.Transform
+	shape     *physics.SurfaceAerodynamicShape
+}
+
+// debugLink connects the anchors of a constraint between two bodies. The
+// offsets are the radii of the constraint, in the local space of the
+// respective body.
+type debugLink struct {
+	primary         physics.Body
+	primaryOffset   dprec.Vec3
+	secondary       physics.Body
+	secondaryOffset dprec.Vec3
+}
+
+func NewPhysicsDebugRenderer(scene *physics.Scene, debug *graphics.Debug) *PhysicsDebugRenderer {
+	return &PhysicsDebugRenderer{
+		scene: scene,
+		debug: debug,
+	}
+}
+
+// PhysicsDebugRenderer draws the collision shapes, aerodynamic forces,
+// constraint links and velocities of the physics scene as debug lines.
+type PhysicsDebugRenderer struct {
+	scene *physics.Scene
+	debug *graphics.Debug
+}
+
+// Render replaces the debug lines with the current state of the physics
+// scene. Balls can be nil.
+func (r *PhysicsDebugRenderer) Render(airplanes []*Airplane, balls []*Ball) {
+	r.debug.Reset()
+	r.scene.Each(func(body physics.Body) {
+		collisionSet := body.CollisionSet()
+		for _, box := range collisionSet.Boxes() {
+			r.renderBox(box)
+		}
+		for _, sphere := range collisionSet.Spheres() {
+			r.renderSphere(sphere)
+		}
+		r.renderVector(body.Position(), dprec.Vec3Prod(body.Velocity(), debugVelocityScale), debugVelocityColor)
+	})
+	for _, airplane := range airplanes {
+		r.renderSurfaces(airplane.debugSurfaces)
+		r.renderLinks(airplane.debugLinks)
+	}
+	for _, ball := range balls {
+		if ball != nil {
+			r.renderSurfaces(ball.debugSurfaces)
+			r.renderLinks(ball.debugLinks)
+		}
+	}
+}
+
+// Clear removes all debug lines.
+func (r *PhysicsDebugRenderer) Clear() {
+	r.debug.Reset()
+}
+
+func (r *PhysicsDebugRenderer) renderBox(box collision.Box) {
+	rotation := box.Rotation()
+	corner := func(x, y, z float64) dprec.Vec3 {
+		offset := dprec.NewVec3(x*box.HalfWidth(), y*box.HalfHeight(), z*box.HalfLength())
+		return dprec.Vec3Sum(box.Position(), dprec.QuatVec3Rotation(rotation, offset))
+	}
+	for _, z := range [...]float64{-1.0, 1.0} {
+		r.debug.Line(corner(-1.0, -1.0, z), corner(1.0, -1.0, z), debugCollisionColor)
+		r.debug.Line(corner(1.0, -1.0, z), corner(1.0, 1.0, z), debugCollisionColor)
+		r.debug.Line(corner(1.0, 1.0, z), corner(-1.0, 1.0, z), debugCollisionColor)
+		r.debug.Line(corner(-1.0, 1.0, z), corner(-1.0, -1.0, z), debugCollisionColor)
+	}
+	for _, x := range [...]float64{-1.0, 1.0} {
+		for _, y := range [...]float64{-1.0, 1.0} {
+			r.debug.Line(corner(x, y, -1.0), corner(x, y, 1.0), debugCollisionColor)
+		}
+	}
+}
+
+func (r *PhysicsDebugRenderer) renderSphere(sphere collision.Sphere) {
+	center := sphere.Position()
+	radius := sphere.Radius()
+	axes := [...][2]dprec.Vec3{
+		{dprec.BasisXVec3(), dprec.BasisYVec3()},
+		{dprec.BasisYVec3(), dprec.BasisZVec3()},
+		{dprec.BasisZVec3(), dprec.BasisXVec3()},
+	}
+	for _, axis := range axes {
+		point := func(index int) dprec.Vec3 {
+			angle := dprec.Degrees(360.0 * float64(index) / debugSphereSegments)
+			return dprec.Vec3Sum(center, dprec.Vec3Sum(
+				dprec.Vec3Prod(axis[0], radius*dprec.Cos(angle)),
+				dprec.Vec3Prod(axis[1], radius*dprec.Sin(angle)),
+			))
+		}
+		for i := 0; i < debugSphereSegments; i++ {
+			r.debug.Line(point(i), point(i+1), debugCollisionColor)
+		}
+	}
+}
+
+// renderSurfaces draws the normal of each surface along with the lift and
+// drag that it produces, calculated the same way as the physics engine does.
+func (r *PhysicsDebugRenderer) renderSurfaces(surfaces []debugSurface) {
+	medium := r.scene.MediumSolver()
+	for _, surface := range surfaces {
+		body := surface.body
+		transform := surface.transform.Transformed(physics.NewTransform(body.Position(), body.Rotation()))
+		position := transform.Position()
+		rotation := transform.Rotation()
+
+		normal := dprec.QuatVec3Rotation(rotation, dprec.BasisYVec3())
+		r.renderVector(position, dprec.Vec3Prod(normal, debugNormalLength), debugNormalColor)
+
+		if medium == nil {
+			continue
+		}
+		windVelocity := dprec.Vec3Diff(medium.Velocity(body.Position()), body.Velocity())
+		if windVelocity.Length() < debugMinLength {
+			continue
+		}
+		relativeWind := dprec.QuatVec3Rotation(dprec.InverseQuat(rotation), windVelocity)
+		force := dprec.QuatVec3Rotation(rotation, surface.shape.Force(relativeWind, medium.Density(body.Position())))
+
+		windDirection := dprec.UnitVec3(windVelocity)
+		drag := dprec.Vec3Prod(windDirection, dprec.Vec3Dot(force, windDirection))
+		lift := dprec.Vec3Diff(force, drag)
+		r.renderVector(position, dprec.Vec3Prod(lift, debugForceScale), debugLiftColor)
+		r.renderVector(position, dprec.Vec3Prod(drag, debugForceScale), debugDragColor)
+	}
+}
+
+func (r *PhysicsDebugRenderer) renderLinks(links []debugLink) {
+	for _, link := range links {
+		from := dprec.Vec3Sum(link.primary.Position(), dprec.QuatVec3Rotation(link.primary.Rotation(), link.primaryOffset))
+		to := dprec.Vec3Sum(link.secondary.Position(), dprec.QuatVec3Rotation(link.secondary.Rotation(), link.secondaryOffset))
+		r.debug.Line(from, to, debugLinkColor)
+	}
+}
+
+func (r *PhysicsDebugRenderer) renderVector(origin, vector dprec.Vec3, color dprec.Vec3) {
+	if vector.Length() < debugMinLength {
+		return
+	}
+	r.debug.Line(origin, dprec.Vec3Sum(origin, vector), color)
+}
+
+// SetPhysicsDebug controls whether the physics of the scene are drawn as
+// debug lines.
+func (c *PlayController) SetPhysicsDebug(enabled bool) {
+	if c.physicsDebug != nil {
+		c.physicsDebug.Clear()
+		c.physicsDebug = nil
+	}
+	if enabled {
+		c.physicsDebug = NewPhysicsDebugRenderer(c.physicsScene, c.engine.Graphics().Debug())
+	}
+}
+
+// PhysicsDebug returns whether the physics of the scene are drawn.
+func (c *PlayController) PhysicsDebug() bool {
+	return c.physicsDebug != nil
+}
+
+func (c *PlayController) renderPhysicsDebug() {
+	if c.physicsDebug == nil {
+		return
+	}
+	airplanes := make([]*Airplane, len(c.players))
+	balls := make([]*Ball, len(c.players))
+	for i, player := range c.players {
+		airplanes[i] = player.Airplane
+		balls[i] = player.Ball
+	}
+	c.physicsDebug.Render(airplanes, balls)
+}
@@ -2,14 +2,18 @@ package controller
 
 import (
 	"fmt"
+	"math/rand"
 	"runtime"
 	"time"
 
 	"github.com/mokiat/ggj2024/internal/game/data"
+	"github.com/mokiat/ggj2024/internal/game/netplay"
+	"github.com/mokiat/ggj2024/internal/game/render"
 	"github.com/mokiat/gomath/dprec"
 	"github.com/mokiat/gomath/sprec"
 	"github.com/mokiat/lacking/app"
 	"github.com/mokiat/lacking/audio"
+	"github.com/mokiat/lacking/debug/log"
 	"github.com/mokiat/lacking/game"
 	"github.com/mokiat/lacking/game/ecs"
 	"github.com/mokiat/lacking/game/graphics"
@@ -27,23 +31,37 @@ const (
 )
 
 const (
-	defeatAfter = 120 * time.Second
+	impactFadeSpeed = 0.5
+)
+
+const (
+	introDelay = 1 * time.Second
+	pilotDelay = 90 * time.Second
+	towerDelay = 45 * time.Second
+)
 
-	victoryAfter = 10
+const (
+	cowRespawnDelay = 10 * time.Second
+)
+
+const (
+	playerSpacing = 40.0
 )
 
-func NewPlayController(window app.Window, audioAPI audio.API, engine *game.Engine, playData *data.PlayData) *PlayController {
+func NewPlayController(window app.Window, audioAPI audio.API, engine *game.Engine, split *render.SplitController, playData *data.PlayData, mode GameMode) *PlayController {
 	return &PlayController{
 		window:   window,
 		audioAPI: audioAPI,
 		engine:   engine,
+		split:    split,
 		playData: playData,
+		mode:     mode,
 
 		lastRubbingTime: time.Now().Add(-time.Minute),
 
-		introAfter: 1 * time.Second,
-		pilotAfter: 90 * time.Second,
-		towerAfter: 45 * time.Second,
+		introAfter: introDelay,
+		pilotAfter: pilotDelay,
+		towerAfter: towerDelay,
 	}
 }
 
@@ -51,30 +69,38 @@ type PlayController struct {
 	window   app.Window
 	audioAPI audio.API
 	engine   *game.Engine
+	split    *render.SplitController
 	playData *data.PlayData
+	mode     GameMode
 
 	preUpdateSubscription  *timestep.UpdateSubscription
 	postUpdateSubscription *timestep.UpdateSubscription
+	collisions             *CollisionDispatcher
 
 	scene        *game.Scene
 	gfxScene     *graphics.Scene
 	physicsScene *physics.Scene
 	ecsScene     *ecs.Scene
 
-	followCameraSystem         *preset.FollowCameraSystem
-	airplaneGamepadController  *AirplaneGamepadController
-	airplaneKeyboardController *AirplaneKeyboardController
+	controls              data.ControlSettings
+	events                GameEventBus
+	telemetry             *TelemetryRecorder
+	telemetrySubscription *physics.UpdateSubscription
+	physicsDebug          *PhysicsDebugRenderer
+	devices               *DeviceManager
+	onDisconnect          func(*Player)
 
-	airplane   *Airplane
-	ball       *Ball
+	followCameraSystem *preset.FollowCameraSystem
+	cowSystem          *CowSystem
+	windSystem         *WindSystem
+
+	players    []*Player
 	cowSpawner *CowSpawner
 	cows       []*Cow
 
 	binNode *hierarchy.Node
-	camera  *graphics.Camera
 
 	soundtrackPlayback audio.Playback
-	popSound           audio.Media
 	rubbingSound       audio.Media
 	lastRubbingTime    time.Time
 
@@ -84,16 +110,31 @@ type PlayController struct {
 	pilotAfter time.Duration
 	towerSound audio.Media
 	towerAfter time.Duration
+	callSound  audio.Media
+
+	gameTime    time.Duration
+	cowRespawns []cowRespawn
 
-	gameTime time.Duration
+	server          *netplay.Server
+	client          *netplay.Client
+	snapshotTick    uint32
+	snapshotElapsed time.Duration
+	inputSequence   uint32
+	predictor       netplay.Predictor
+	correction      netplay.BodyState
 
 	onVictory func(time.Duration)
 	onDefeat  func(int)
+
+	victoryCallback func(time.Duration)
+	defeatCallback  func(int)
 }
 
 func (c *PlayController) Start(onVictory func(time.Duration), onDefeat func(int)) {
 	c.onVictory = onVictory
 	c.onDefeat = onDefeat
+	c.victoryCallback = onVictory
+	c.defeatCallback = onDefeat
 
 	c.scene = c.engine.CreateScene()
 	c.scene.Initialize(c.playData.Scene)
@@ -118,64 +159,39 @@ func (c *PlayController) Start(onVictory func(time.Duration), onDefeat func(int)
 	c.followCameraSystem.UseDefaults()
 
 	c.physicsScene.CreateGlobalAccelerator(acceleration.NewGravityDirection())
+	wind := c.playData.Level.Wind
+	payload := data.PayloadNormal
+	challenge := c.Challenge()
+	if challenge != nil {
+		wind = challenge.Wind(wind)
+		payload = challenge.Payload()
+	}
+	c.windSystem = NewWindSystem(wind)
+	c.physicsScene.SetMediumSolver(c.windSystem)
 
-	airplanePosition := dprec.NewVec3(0.0, 100.0, 0.0)
-	airplaneModel := c.scene.CreateModel(game.ModelInfo{
-		Definition:        c.playData.Airplane,
-		Name:              "Airplane",
-		Position:          airplanePosition,
-		Rotation:          dprec.IdentityQuat(),
-		Scale:             dprec.NewVec3(1.0, 1.0, 1.0),
-		IsDynamic:         true,
-		PrepareAnimations: true,
-	})
-	c.airplane = NewAirplane(c.physicsScene, c.ecsScene, airplaneModel, airplanePosition)
-
-	ballModel := c.scene.CreateModel(game.ModelInfo{
-		Definition:        c.playData.Ball,
-		Name:              "Ball",
-		Position:          dprec.ZeroVec3(),
-		Rotation:          dprec.IdentityQuat(),
-		Scale:             dprec.NewVec3(1.0, 1.0, 1.0),
-		IsDynamic:         true,
-		PrepareAnimations: true,
-	})
-	c.ball = NewBall(c.physicsScene, c.airplane, ballModel)
-
-	c.airplaneKeyboardController = NewAirplaneKeyboardController(c.airplane)
-
-	c.camera = c.gfxScene.CreateCamera()
-	c.camera.SetFoVMode(graphics.FoVModeHorizontalPlus)
-	c.camera.SetFoV(sprec.Degrees(60))
-	c.camera.SetAutoExposure(false)
-	c.camera.SetExposure(2.0)
-	c.camera.SetAutoFocus(false)
-	c.gfxScene.SetActiveCamera(c.camera)
-
-	cameraNode := c.scene.Root().FindNode("Camera")
-	cameraNode.SetTarget(game.CameraNodeTarget{
-		Camera: c.camera,
-	})
-	cameraNode.SetPosition(dprec.Vec3Sum(
-		c.airplane.Body.Position(),
-		dprec.NewVec3(0.0, 50.0, -cameraDistance),
-	))
-	cameraNode.ApplyToTarget(false)
-
-	cameraEntity := c.ecsScene.CreateEntity()
-	ecs.AttachComponent(cameraEntity, &preset.NodeComponent{
-		Node: cameraNode,
-	})
-	targetNode := c.airplane.Node
-	ecs.AttachComponent(cameraEntity, &preset.FollowCameraComponent{
-		Target:         targetNode,
-		AnchorPosition: dprec.Vec3Sum(c.airplane.Body.Position(), dprec.NewVec3(0.0, 2.0, -cameraDistance)),
-		AnchorDistance: anchorDistance,
-		CameraDistance: cameraDistance,
-		PitchAngle:     dprec.Degrees(-30),
-		YawAngle:       dprec.Degrees(0),
-		Zoom:           1.0,
-	})
+	playerCount := 1
+	if multiplayer, ok := c.mode.(MultiplayerGameMode); ok {
+		playerCount = multiplayer.Players()
+	}
+	inputs := AssignPlayerInputs(playerCount, c.window.Gamepads())
+	if c.Online() {
+		inputs = c.networkInputs(playerCount)
+	}
+	c.devices = NewDeviceManager(c.window)
+	var cameras []*graphics.Camera
+	for i := 0; i < playerCount; i++ {
+		player := c.createPlayer(i, inputs[i], payload)
+		c.players = append(c.players, player)
+		if player.Input != PlayerInputRemote {
+			cameras = append(cameras, player.camera)
+		}
+	}
+	c.UpdateDevices()
+	localPlayers := c.LocalPlayers()
+	c.gfxScene.SetActiveCamera(localPlayers[0].camera)
+	if len(localPlayers) > 1 {
+		c.split.SetCameras(cameras...)
+	}
 
 	lightNode := c.scene.Root().FindNode("Light")
 	lightNode.UseTransformation(func(node *hierarchy.Node) dprec.Mat4 {
@@ -192,21 +208,67 @@ func (c *PlayController) Start(onVictory func(time.Duration), onDefeat func(int)
 		base.M33 = 1.0
 		return dprec.Mat4Prod(base, node.Matrix())
 	})
-	c.airplane.Node.AppendChild(lightNode)
+	localPlayers[0].Airplane.Node.AppendChild(lightNode)
+	if sun := c.playData.Level.Lighting().Sun; sun != nil {
+		lightNode.SetRotation(sun.Rotation())
+		if target, ok := lightNode.Target().(game.DirectionalLightNodeTarget); ok {
+			color := target.Light.EmitColor()
+			target.Light.SetEmitColor(dprec.NewVec3(
+				color.X*sun.Tint.X,
+				color.Y*sun.Tint.Y,
+				color.Z*sun.Tint.Z,
+			))
+		}
+	}
+	// The light follows the airplane of the first player, so the other
+	// split-screen views move it to their own airplane, in order to keep
+	// it within shadow range.
+	if target, ok := lightNode.Target().(game.DirectionalLightNodeTarget); ok && len(localPlayers) > 1 {
+		c.split.SetPrepare(func(index int) {
+			target.Light.SetPosition(dprec.Vec3Sum(
+				localPlayers[index].Airplane.Node.AbsoluteMatrix().Translation(),
+				lightNode.Position(),
+			))
+		})
+	}
 
-	c.cowSpawner = NewCowSpawner(c.scene, c.playData.Cow, c.playData.Burst)
+	c.cowSpawner = NewCowSpawner(c.scene, c.playData.CowModels)
 
-	index := 0
-	for {
-		index++
-		name := fmt.Sprintf("Cow.%03d", index)
-		node := c.scene.Root().FindNode(name)
+	var candidates []*hierarchy.Node
+	for index := 1; ; index++ {
+		node := c.scene.Root().FindNode(fmt.Sprintf("Cow.%03d", index))
 		if node == nil {
 			break
 		}
-		cow := c.cowSpawner.SpawnCow(node.Position())
+		candidates = append(candidates, node)
+	}
+	var layout map[string]*data.CowArchetype
+	if challenge != nil {
+		names := make([]string, len(candidates))
+		for i, node := range candidates {
+			names[i] = node.Name()
+		}
+		layout = challenge.CowLayout(names)
+	}
+	for _, node := range candidates {
+		name := node.Name()
+		archetype := c.playData.Level.CowArchetype(name)
+		if layout != nil {
+			var ok bool
+			if archetype, ok = layout[name]; !ok {
+				continue
+			}
+		}
+		behaviour := c.playData.CowBehaviours.Node(name)
+		cow := c.cowSpawner.SpawnCow(node.Position(), archetype, behaviour)
 		c.cows = append(c.cows, cow)
 	}
+	c.spawnZoneCows(challenge)
+	airplanes := make([]*Airplane, len(c.players))
+	for i, player := range c.players {
+		airplanes[i] = player.Airplane
+	}
+	c.cowSystem = NewCowSystem(c.ecsScene, c.playData.Terrain, airplanes...)
 
 	runtime.GC()
 	c.engine.ResetDeltaTime()
@@ -216,105 +278,373 @@ func (c *PlayController) Start(onVictory func(time.Duration), onDefeat func(int)
 		Gain: 1.0,
 		Loop: true,
 	})
-	c.popSound = c.playData.Pop
 	c.rubbingSound = c.playData.Rubbing
 	c.introSound = c.playData.IntroSound
 	c.pilotSound = c.playData.PilotSound
 	c.towerSound = c.playData.TowerSound
+	c.callSound = c.playData.TowerCallback
 
-	c.physicsScene.SubscribeDoubleBodyCollision(func(first physics.Body, second physics.Body, active bool) {
-		var sourceBody physics.Body
-		var targetBody physics.Body
-		switch {
-		case first == c.ball.Body || first == c.airplane.Body:
-			sourceBody = first
-			targetBody = second
-		case second == c.ball.Body || second == c.airplane.Body:
-			sourceBody = second
-			targetBody = first
-		default:
-			return
-		}
+	c.collisions = NewCollisionDispatcher(c.physicsScene)
+	for _, player := range c.players {
+		c.collisions.Register(player.Airplane.Body, player.Airplane.Entity)
+		c.collisions.Register(player.Ball.Body, player.Ball.Entity)
+	}
+	for _, cow := range c.cows {
+		c.collisions.Register(cow.Body, cow.Entity)
+	}
+	c.collisions.Bus.BallCow.Subscribe(c.onBallCowContact)
+	c.collisions.Bus.AirplaneCow.Subscribe(c.onAirplaneCowContact)
+	c.collisions.Bus.AirplaneCrash.Subscribe(c.onAirplaneCrash)
+}
+
+// createPlayer creates the airplane and ball rig of a player, together with
+// a camera that follows it. Players start next to each other.
+func (c *PlayController) createPlayer(index int, input PlayerInput, payload data.Payload) *Player {
+	player := &Player{
+		Index:      index,
+		Input:      input,
+		controller: c,
+		gamepad:    -1,
+		feedback:   NewHapticFeedback(NopHaptics{}),
+	}
+
+	airplanePosition := dprec.NewVec3(float64(index)*playerSpacing, 100.0, 0.0)
+	airplaneModel := c.scene.CreateModel(game.ModelInfo{
+		Definition:        c.playData.Airplane,
+		Name:              "Airplane",
+		Position:          airplanePosition,
+		Rotation:          dprec.IdentityQuat(),
+		Scale:             dprec.NewVec3(1.0, 1.0, 1.0),
+		IsDynamic:         true,
+		PrepareAnimations: true,
+	})
+	player.Airplane = NewAirplane(c.physicsScene, c.ecsScene, airplaneModel, airplanePosition)
+	player.stats = NewStatsRecorder(player.Airplane)
+
+	ballModel := c.scene.CreateModel(game.ModelInfo{
+		Definition:        c.playData.Ball,
+		Name:              "Ball",
+		Position:          dprec.ZeroVec3(),
+		Rotation:          dprec.IdentityQuat(),
+		Scale:             dprec.NewVec3(1.0, 1.0, 1.0),
+		IsDynamic:         true,
+		PrepareAnimations: true,
+	})
+	player.Ball = NewBall(c.physicsScene, c.ecsScene, player.Airplane, ballModel, payload)
+	player.checkpointSystem = NewCheckpointSystem(player.Airplane, player.Ball, c.playData.Level.Bounds)
+	player.boundsSystem = NewBoundsSystem(c.playData.Level.Bounds, player.Airplane)
+
+	player.camera = c.gfxScene.CreateCamera()
+	player.camera.SetFoVMode(graphics.FoVModeHorizontalPlus)
+	player.camera.SetFoV(sprec.Degrees(60))
+	player.camera.SetAutoExposure(false)
+	player.camera.SetExposure(c.playData.Level.Lighting().Exposure)
+	player.camera.SetAutoFocus(false)
+
+	// The first player uses the camera node of the scene, so that any
+	// setup done in the scene still applies in single player.
+	if index == 0 {
+		player.cameraNode = c.scene.Root().FindNode("Camera")
+	} else {
+		player.cameraNode = hierarchy.NewNode()
+		c.scene.Root().AppendChild(player.cameraNode)
+	}
+	player.cameraNode.SetTarget(game.CameraNodeTarget{
+		Camera: player.camera,
+	})
+	if input == PlayerInputAuto || input == PlayerInputKeyboard {
+		player.useKeyboard()
+	}
 
-		for _, cow := range c.cows {
-			if !cow.Active {
+	cameraEntity := c.ecsScene.CreateEntity()
+	ecs.AttachComponent(cameraEntity, &preset.NodeComponent{
+		Node: player.cameraNode,
+	})
+	player.followCamera = &preset.FollowCameraComponent{
+		Target:         player.Airplane.Node,
+		AnchorDistance: anchorDistance,
+		CameraDistance: cameraDistance,
+		PitchAngle:     dprec.Degrees(-30),
+		YawAngle:       dprec.Degrees(0),
+		Zoom:           1.0,
+	}
+	ecs.AttachComponent(cameraEntity, player.followCamera)
+	player.resetCamera()
+
+	return player
+}
+
+// spawnZoneCows places cows procedurally in the spawn zones of the level.
+func (c *PlayController) spawnZoneCows(challenge *data.Challenge) {
+	level := c.playData.Level
+	if len(level.SpawnZones) == 0 || c.playData.Terrain == nil {
+		return
+	}
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	if seed, ok := c.networkSeed(); ok {
+		random = rand.New(rand.NewSource(seed))
+	}
+	if challenge != nil {
+		random = challenge.SpawnRandom()
+	}
+	taken := make([]dprec.Vec3, len(c.cows))
+	for i, cow := range c.cows {
+		taken[i] = cow.Position()
+	}
+	for i := range level.SpawnZones {
+		zone := &level.SpawnZones[i]
+		var center dprec.Vec3
+		if !zone.UsesPolygon() {
+			node := c.scene.Root().FindNode(zone.Node)
+			if node == nil {
+				log.Warn("Spawn zone %q references missing node %q", zone.Name, zone.Node)
 				continue
 			}
-			if cow.Body == targetBody {
-				if sourceBody == c.ball.Body {
-					c.audioAPI.Play(c.popSound, audio.PlayInfo{
-						Gain: 1.0,
-					})
-					cow.Burst(c.scene)
-				}
-				if sourceBody == c.airplane.Body && time.Since(c.lastRubbingTime) > time.Second {
-					c.audioAPI.Play(c.rubbingSound, audio.PlayInfo{
-						Gain: 1.0,
-					})
-					c.lastRubbingTime = time.Now()
-				}
-			}
+			center = node.AbsoluteMatrix().Translation()
 		}
-	})
+		positions, err := spawnZonePositions(zone, center, c.playData.Terrain, random, taken)
+		if err != nil {
+			log.Warn("Spawn zone %q could not be sampled: %v", zone.Name, err)
+		}
+		if len(positions) < zone.Count {
+			log.Warn("Spawn zone %q fits only %d of %d cows", zone.Name, len(positions), zone.Count)
+		}
+		archetype := level.SpawnZoneArchetype(zone)
+		behaviour := c.playData.CowBehaviours.SpawnZone(zone)
+		for _, position := range positions {
+			cow := c.cowSpawner.SpawnCow(position, archetype, behaviour)
+			c.cows = append(c.cows, cow)
+		}
+		taken = append(taken, positions...)
+	}
+}
+
+// Challenge returns the challenge that defines the layout of the level,
+// if the game mode has one.
+func (c *PlayController) Challenge() *data.Challenge {
+	if seeded, ok := c.mode.(SeededGameMode); ok {
+		return seeded.Challenge()
+	}
+	return nil
+}
+
+// SoftReset restarts the game inside the current scene, putting the
+// airplane, the ball and all cows back to their initial state.
+func (c *PlayController) SoftReset() {
+	if c.client != nil {
+		return // only the server can restart a network game
+	}
+	for _, player := range c.players {
+		player.reset()
+	}
+	c.windSystem.Reset()
+	c.cowSpawner.ResetEffects()
+	for _, cow := range c.cows {
+		burst := !cow.Active
+		c.cowSpawner.ResetCow(cow)
+		if burst {
+			c.collisions.Register(cow.Body, cow.Entity)
+		}
+	}
+
+	c.gameTime = 0
+	c.cowRespawns = nil
+	c.introAfter = introDelay
+	c.pilotAfter = pilotDelay
+	c.towerAfter = towerDelay
+	c.lastRubbingTime = time.Now().Add(-time.Minute)
+
+	c.onVictory = c.victoryCallback
+	c.onDefeat = c.defeatCallback
+	c.engine.ResetDeltaTime()
+	c.scene.Unfreeze()
+}
+
+// Respawn returns the airplane of the keyboard player to the last
+// checkpoint at the cost of some time.
+func (c *PlayController) Respawn() {
+	for _, player := range c.players {
+		if player.keyboardController != nil || player.Input == PlayerInputAuto {
+			player.Respawn()
+		}
+	}
+}
+
+// Players returns the players of the game, in the order in which their
+// views are stacked on the screen.
+func (c *PlayController) Players() []*Player {
+	return c.players
+}
+
+// Winner returns the index of the player that won the game or -1 if there
+// is no single winner.
+func (c *PlayController) Winner() int {
+	if multiplayer, ok := c.mode.(MultiplayerGameMode); ok {
+		return multiplayer.Winner(c.status())
+	}
+	return -1
 }
 
 func (c *PlayController) Freeze() {
 	c.scene.Freeze()
 }
 
+// Unfreeze resumes a game that was paused with Freeze.
+func (c *PlayController) Unfreeze() {
+	c.engine.ResetDeltaTime()
+	c.scene.Unfreeze()
+}
+
 func (c *PlayController) Stop() {
 	c.soundtrackPlayback.Stop()
+	c.split.SetCameras()
+	c.split.SetPrepare(nil)
 	c.engine.SetActiveScene(nil)
 	c.preUpdateSubscription.Delete()
 	c.postUpdateSubscription.Delete()
+	c.collisions.Delete()
 	c.scene.Delete()
+	c.closeNetwork()
+	c.SetPhysicsDebug(false)
+	if err := c.StopTelemetry(); err != nil {
+		log.Warn("Failed to finish telemetry: %v", err)
+	}
+}
+
+// HUD returns the parts of the HUD that the game mode needs.
+func (c *PlayController) HUD() HUDConfig {
+	return c.mode.HUD()
+}
+
+// CowCount returns the number shown on the cows counter.
+func (c *PlayController) CowCount() int {
+	return c.mode.Counter(c.status())
+}
+
+// RemainingCows returns the number of cows that have not been popped.
+func (c *PlayController) RemainingCows() int {
+	var count int
+	for _, cow := range c.cows {
+		if cow.Active {
+			count++
+		}
+	}
+	return count
+}
+
+// DisplayTime returns the time shown on the timer.
+func (c *PlayController) DisplayTime() time.Duration {
+	return c.mode.Timer(c.status())
+}
+
+func (c *PlayController) WindSpeed() float64 {
+	wind := c.windSystem.Wind()
+	return dprec.NewVec3(wind.X, 0.0, wind.Z).Length()
 }
 
-func (c *PlayController) CowsRemaining() int {
-	return max(0, victoryAfter-c.poppedCows())
+// Events returns the bus on which gameplay events are published.
+func (c *PlayController) Events() *GameEventBus {
+	return &c.events
 }
 
-func (c *PlayController) RemainingTime() time.Duration {
-	return max(0, defeatAfter-c.gameTime)
+// UseControls applies the control preferences of the player. It needs to
+// be called before Start.
+func (c *PlayController) UseControls(controls data.ControlSettings) {
+	c.controls = controls
 }
 
 func (c *PlayController) OnMouseEvent(element *ui.Element, event ui.MouseEvent) bool {
+	// The views of local players are stacked on the screen, so the mouse
+	// aims within the view of the player that flies with it.
+	players := c.LocalPlayers()
+	size := element.Bounds().Size
+	for i, player := range players {
+		if player.mouseController == nil || player.gamepadController != nil {
+			continue
+		}
+		height := size.Height / len(players)
+		bounds := ui.NewBounds(0, i*height, size.Width, height)
+		return player.mouseController.OnMouseEvent(event, bounds)
+	}
 	return false
 }
 
 func (c *PlayController) OnKeyboardEvent(event ui.KeyboardEvent) bool {
-	if c.airplaneKeyboardController == nil {
-		return false
+	for _, player := range c.players {
+		if player.keyboardController != nil && player.gamepadController == nil {
+			return player.keyboardController.OnKeyboardEvent(event)
+		}
 	}
-	return c.airplaneKeyboardController.OnKeyboardEvent(event)
+	return false
 }
 
 func (c *PlayController) onPreUpdate(elapsedTime time.Duration) {
-	if c.airplaneGamepadController == nil {
-		gamepad := c.window.Gamepads()[0]
-		if gamepad.Connected() && gamepad.Supported() {
-			c.airplaneGamepadController = NewAirplaneGamepadController(c.airplane, gamepad)
-			c.airplaneKeyboardController = nil
+	c.UpdateDevices()
+	for _, player := range c.players {
+		player.updateInput(elapsedTime.Seconds())
+		if player.respawnPending {
+			player.respawnPending = false
+			player.checkpointSystem.Respawn()
+			player.penalty += respawnPenalty
 		}
 	}
-	if c.airplaneGamepadController != nil {
-		c.airplaneGamepadController.Update(elapsedTime.Seconds())
+	switch {
+	case c.server != nil:
+		c.applyRemoteInputs()
+	case c.client != nil:
+		c.sendLocalInput()
+		c.applySnapshot(elapsedTime)
+	}
+	c.windSystem.Update(elapsedTime.Seconds())
+	for _, player := range c.players {
+		player.Airplane.UpdatePhysics(elapsedTime.Seconds())
 	}
-	if c.airplaneKeyboardController != nil {
-		c.airplaneKeyboardController.Update(elapsedTime.Seconds())
+	// Cows of a network game move on the server only.
+	if c.client == nil {
+		c.cowSystem.Update(elapsedTime.Seconds())
 	}
-	c.airplane.UpdatePhysics(elapsedTime.Seconds())
 }
 
 func (c *PlayController) onPostUpdate(elapsedTime time.Duration) {
+	c.renderPhysicsDebug()
 	if c.onVictory == nil || c.onDefeat == nil {
 		return
 	}
-
 	c.followCameraSystem.Update(elapsedTime.Seconds())
+	for _, player := range c.players {
+		if c.client != nil && player.Input == PlayerInputRemote {
+			continue // the server handles checkpoints and bounds
+		}
+		player.checkpointSystem.Update(elapsedTime)
+		switch player.boundsSystem.Update(elapsedTime) {
+		case BoundsEventLeft:
+			c.audioAPI.Play(c.callSound, audio.PlayInfo{
+				Gain: 1.0,
+			})
+		case BoundsEventExpired:
+			if c.outOfBoundsAction(player) == data.OutOfBoundsDefeat {
+				c.publishFinished(GameOutcomeDefeat)
+				c.onDefeat(c.RemainingCows())
+				c.onDefeat = nil
+				return
+			}
+			player.Respawn()
+		}
+		player.impactStrength = max(0.0, player.impactStrength-impactFadeSpeed*elapsedTime.Seconds())
+		if stall := player.Airplane.StallAmount(); stall > 0.0 {
+			player.feedback.Handle(HapticEvent{
+				Kind:     HapticEventStall,
+				Strength: stall,
+			})
+		}
+		player.feedback.Update(elapsedTime)
+		player.stats.Update(elapsedTime)
+	}
+	c.updateCowRespawns(elapsedTime)
 	for _, cow := range c.cows {
 		cow.Update(elapsedTime)
 	}
+	c.cowSpawner.Update(elapsedTime)
 	c.introAfter -= elapsedTime
 	if c.introAfter < 0 {
 		c.audioAPI.Play(c.introSound, audio.PlayInfo{
@@ -337,27 +667,255 @@ func (c *PlayController) onPostUpdate(elapsedTime time.Duration) {
 		c.towerAfter = 24 * time.Hour
 	}
 
-	countCows := c.CowsRemaining()
-	if countCows == 0 {
-		c.onVictory(c.gameTime)
+	if c.client != nil {
+		c.recordPrediction()
+		c.updateClientOutcome()
+		return
+	}
+
+	if c.mode.Outcome(c.status()) == GameOutcomeVictory {
+		if c.server != nil {
+			c.updateServer(elapsedTime, true)
+		}
+		c.publishFinished(GameOutcomeVictory)
+		c.onVictory(c.finishTime())
 		c.onVictory = nil
 		return
 	}
 
 	c.gameTime += elapsedTime
-	if c.gameTime > defeatAfter {
-		c.onDefeat(countCows)
+	if c.mode.Outcome(c.status()) == GameOutcomeDefeat {
+		if c.server != nil {
+			c.updateServer(elapsedTime, true)
+		}
+		c.publishFinished(GameOutcomeDefeat)
+		c.onDefeat(c.RemainingCows())
 		c.onDefeat = nil
 		return
 	}
+	if c.server != nil {
+		c.updateServer(elapsedTime, false)
+	}
 }
 
-func (c *PlayController) poppedCows() int {
-	var count int
+// updateClientOutcome ends the game of a client when the server says so.
+func (c *PlayController) updateClientOutcome() {
+	// The final snapshot is checked first, since the server may say
+	// goodbye right after it.
+	if latest, ok := c.client.Latest(); ok && latest.Finished {
+		c.publishFinished(GameOutcomeVictory)
+		c.onVictory(c.finishTime())
+		c.onVictory = nil
+		return
+	}
+	if c.client.Disconnected() {
+		log.Warn("Server ended the game")
+		c.publishFinished(GameOutcomeDefeat)
+		c.onDefeat(c.RemainingCows())
+		c.onDefeat = nil
+	}
+}
+
+func (c *PlayController) onBallCowContact(event BallCowContact) {
+	player := c.ballPlayer(event.Ball)
+	if player == nil {
+		return
+	}
+	cow := event.Cow
+	impactSpeed := dprec.Vec3Diff(event.Ball.Body.Velocity(), cow.Body.Velocity()).Length()
+	strength := cow.ImpactStrength(impactSpeed)
+	player.impactStrength = strength
+	player.strongestImpact = max(player.strongestImpact, strength)
+
+	switch cow.Hit(impactSpeed) {
+	case CowHitWobble, CowHitDamage:
+		if time.Since(cow.LastWobbleSound) > cowWobbleSoundCooldown {
+			c.audioAPI.Play(c.playData.CowSounds[cow.Archetype.WobbleSound], audio.PlayInfo{
+				Gain: dprec.Clamp(strength, 0.2, 1.0),
+			})
+			cow.LastWobbleSound = time.Now()
+		}
+		cow.Wobble(c.scene, strength)
+	case CowHitPop:
+		player.feedback.Handle(HapticEvent{
+			Kind:     HapticEventPop,
+			Strength: strength / 2.0,
+		})
+		if c.client != nil {
+			// Only the server decides which cows pop.
+			return
+		}
+		c.audioAPI.Play(c.playData.CowSounds[cow.Archetype.PopSound], audio.PlayInfo{
+			Gain: 1.0,
+		})
+		c.cowSpawner.PlayEffect(cow.Archetype.PopEffect, cow.Model.Root().Position(), cow.Model.Root().Rotation(), cow.Archetype.PopEffectScale)
+		c.collisions.Unregister(cow.Body)
+		cow.Burst()
+		cow.PoppedBy = player.Index
+		player.penalty += cow.Archetype.TimePenalty
+		player.points += cow.Archetype.Points
+		player.pops++
+		if cow.Archetype.Points > 0 {
+			player.scoringPops++
+		}
+		c.events.CowPopped.Publish(CowPopped{
+			Player:   player,
+			Cow:      cow,
+			Time:     c.gameTime,
+			Inverted: player.Airplane.Inverted(),
+		})
+		if c.mode.RespawnCows() {
+			c.cowRespawns = append(c.cowRespawns, cowRespawn{
+				cow:       cow,
+				remaining: cowRespawnDelay,
+			})
+		}
+	}
+}
+
+func (c *PlayController) onAirplaneCowContact(event AirplaneCowContact) {
+	if player := c.airplanePlayer(event.Airplane); player != nil {
+		player.feedback.Handle(HapticEvent{
+			Kind: HapticEventRubbing,
+		})
+		player.stats.RecordRub()
+		c.events.CowRubbed.Publish(CowRubbed{
+			Player: player,
+			Cow:    event.Cow,
+		})
+	}
+	if time.Since(c.lastRubbingTime) > time.Second {
+		c.audioAPI.Play(c.rubbingSound, audio.PlayInfo{
+			Gain: 1.0,
+		})
+		c.lastRubbingTime = time.Now()
+	}
+}
+
+func (c *PlayController) onAirplaneCrash(event AirplaneCrash) {
+	player := c.airplanePlayer(event.Airplane)
+	if player == nil || player.checkpointSystem.Invulnerable() || player.respawnPending {
+		return
+	}
+	if c.client != nil && player.Input == PlayerInputRemote {
+		return
+	}
+	player.feedback.Handle(HapticEvent{
+		Kind:     HapticEventCrash,
+		Strength: dprec.Clamp(event.ImpactSpeed/respawnImpactSpeed, 0.0, 1.0),
+	})
+	if event.ImpactSpeed < respawnImpactSpeed {
+		return
+	}
+	player.crashes++
+	player.stats.RecordCrash()
+	player.respawnPending = true
+}
+
+// outOfBoundsAction returns what happens to the specified player for
+// staying outside of the level bounds for too long.
+func (c *PlayController) outOfBoundsAction(player *Player) data.OutOfBoundsAction {
+	action := player.boundsSystem.Bounds().Action
+	if mode, ok := c.mode.(BoundsGameMode); ok {
+		return mode.OutOfBoundsAction(action)
+	}
+	return action
+}
+
+// finishTime returns the time of the winner, including their penalties,
+// or the time of the whole team when there is no single winner.
+func (c *PlayController) finishTime() time.Duration {
+	if winner := c.Winner(); winner >= 0 {
+		return c.gameTime + c.players[winner].penalty
+	}
+	return c.status().TimeTaken()
+}
+
+func (c *PlayController) publishFinished(outcome GameOutcome) {
+	status := c.status()
+	event := GameFinished{
+		Outcome: outcome,
+		Elapsed: c.finishTime(),
+	}
+	if countdown, ok := c.mode.(CountdownGameMode); ok {
+		event.TimeLeft = countdown.TimeLeft(status)
+		event.Countdown = true
+	}
+	if outcome == GameOutcomeVictory {
+		if winner := c.Winner(); winner >= 0 {
+			event.Winners = []*Player{c.players[winner]}
+		} else {
+			event.Winners = c.players
+		}
+	}
+	c.events.GameFinished.Publish(event)
+}
+
+func (c *PlayController) airplanePlayer(airplane *Airplane) *Player {
+	for _, player := range c.players {
+		if player.Airplane == airplane {
+			return player
+		}
+	}
+	return nil
+}
+
+func (c *PlayController) ballPlayer(ball *Ball) *Player {
+	for _, player := range c.players {
+		if player.Ball == ball {
+			return player
+		}
+	}
+	return nil
+}
+
+// playerCounter returns the number shown on the cows counter of the
+// specified player.
+func (c *PlayController) playerCounter(player *Player) int {
+	status := c.status()
+	if multiplayer, ok := c.mode.(MultiplayerGameMode); ok {
+		return multiplayer.PlayerCounter(status, player.Index)
+	}
+	return c.mode.Counter(status)
+}
+
+func (c *PlayController) status() GameStatus {
+	status := GameStatus{
+		Elapsed: c.gameTime,
+		Players: make([]PlayerStatus, len(c.players)),
+	}
 	for _, cow := range c.cows {
-		if !cow.Active {
-			count++
+		status.MaxScore += cow.Archetype.Points
+	}
+	for i, player := range c.players {
+		status.Players[i] = PlayerStatus{
+			Score:   player.points,
+			Pops:    player.pops,
+			Penalty: player.penalty,
 		}
+		status.Score += player.points
+		status.Pops += player.pops
+		status.ScoringPops += player.scoringPops
+		status.Penalty += player.penalty
 	}
-	return count
+	return status
+}
+
+func (c *PlayController) updateCowRespawns(elapsedTime time.Duration) {
+	pending := c.cowRespawns[:0]
+	for _, respawn := range c.cowRespawns {
+		respawn.remaining -= elapsedTime
+		if respawn.remaining > 0 {
+			pending = append(pending, respawn)
+			continue
+		}
+		c.cowSpawner.ResetCow(respawn.cow)
+		c.collisions.Register(respawn.cow.Body, respawn.cow.Entity)
+	}
+	c.cowRespawns = pending
+}
+
+type cowRespawn struct {
+	cow       *Cow
+	remaining time.Duration
 }
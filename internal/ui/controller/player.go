@@ -0,0 +1,272 @@
+package controller
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/mokiat/gomath/dprec"
+	"github.com/mokiat/lacking/app"
+	"github.com/mokiat/lacking/game/graphics"
+	"github.com/mokiat/lacking/game/hierarchy"
+	"github.com/mokiat/lacking/game/preset"
+)
+
+const (
+	// PlayerInputAuto uses the keyboard until the first gamepad is
+	// connected. It is only used when there is a single player.
+	PlayerInputAuto PlayerInput = -2
+
+	PlayerInputKeyboard PlayerInput = -1
+
+	// PlayerInputRemote is used for players that are controlled over the
+	// network by another machine.
+	PlayerInputRemote PlayerInput = -3
+)
+
+// PlayerInput identifies the device that controls a player. Non-negative
+// values are gamepad indices.
+type PlayerInput int
+
+func PlayerInputGamepad(index int) PlayerInput {
+	return PlayerInput(index)
+}
+
+func (i PlayerInput) String() string {
+	switch i {
+	case PlayerInputAuto:
+		return "Auto"
+	case PlayerInputKeyboard:
+		return "Keyboard"
+	case PlayerInputRemote:
+		return "Online"
+	default:
+		return fmt.Sprintf("Gamepad %d", int(i)+1)
+	}
+}
+
+// AssignPlayerInputs decides which device controls each player. When there
+// are not enough connected gamepads for everyone, the first player uses
+// the keyboard and the rest wait for their gamepads.
+func AssignPlayerInputs(count int, gamepads [4]app.Gamepad) []PlayerInput {
+	if count == 1 {
+		return []PlayerInput{PlayerInputAuto}
+	}
+	var connected []int
+	for i, gamepad := range gamepads {
+		if gamepad.Connected() && gamepad.Supported() {
+			connected = append(connected, i)
+		}
+	}
+	result := make([]PlayerInput, count)
+	if len(connected) >= count {
+		for i := range result {
+			result[i] = PlayerInputGamepad(connected[i])
+		}
+		return result
+	}
+	result[0] = PlayerInputKeyboard
+	for i := 1; i < count; i++ {
+		if i-1 < len(connected) {
+			result[i] = PlayerInputGamepad(connected[i-1])
+		} else {
+			result[i] = PlayerInputGamepad(i - 1)
+		}
+	}
+	return result
+}
+
+// Player holds everything that one person flies with: the airplane and
+// ball rig, the camera that follows it and the input that steers it.
+type Player struct {
+	Index int
+	Input PlayerInput
+
+	Airplane *Airplane
+	Ball     *Ball
+
+	controller *PlayController
+
+	checkpointSystem *CheckpointSystem
+	boundsSystem     *BoundsSystem
+
+	camera       *graphics.Camera
+	cameraNode   *hierarchy.Node
+	followCamera *preset.FollowCameraComponent
+
+	gamepad            int
+	gamepadController  *AirplaneGamepadController
+	keyboardController *AirplaneKeyboardController
+	mouseController    *AirplaneMouseController
+	awaitingDevice     bool
+	feedback           *HapticFeedback
+	stats              *StatsRecorder
+
+	points          int
+	pops            int
+	scoringPops     int
+	crashes         int
+	penalty         time.Duration
+	respawnPending  bool
+	impactStrength  float64
+	strongestImpact float64
+}
+
+// Camera returns the camera that follows the airplane of the player.
+func (p *Player) Camera() *graphics.Camera {
+	return p.camera
+}
+
+// CowCount returns the number shown on the cows counter of the player.
+func (p *Player) CowCount() int {
+	return p.controller.playerCounter(p)
+}
+
+// DisplayTime returns the time shown on the timer.
+func (p *Player) DisplayTime() time.Duration {
+	return p.controller.DisplayTime()
+}
+
+// ImpactStrength returns the strength of the most recent ball impact,
+// relative to what is needed to hit a cow. It fades out over time.
+func (p *Player) ImpactStrength() float64 {
+	return p.impactStrength
+}
+
+func (p *Player) StrongestImpact() float64 {
+	return p.strongestImpact
+}
+
+// WindDirection returns the direction of the wind relative to the heading
+// of the airplane.
+func (p *Player) WindDirection() dprec.Angle {
+	wind := p.controller.windSystem.Wind()
+	forward := p.Airplane.Body.Rotation().OrientationZ()
+	windYaw := math.Atan2(wind.X, wind.Z)
+	headingYaw := math.Atan2(forward.X, forward.Z)
+	return dprec.Radians(windYaw - headingYaw)
+}
+
+func (p *Player) WindSpeed() float64 {
+	return p.controller.WindSpeed()
+}
+
+// OutOfBounds returns whether the airplane has left the level and how much
+// time remains until it is brought back.
+func (p *Player) OutOfBounds() (time.Duration, bool) {
+	return p.boundsSystem.OutOfBounds()
+}
+
+// Respawn returns the airplane to the last checkpoint at the cost of
+// some time.
+func (p *Player) Respawn() {
+	p.respawnPending = true
+}
+
+func (p *Player) reset() {
+	p.Airplane.Reset()
+	p.Ball.Reset()
+	p.checkpointSystem.Reset()
+	p.boundsSystem.Reset()
+	p.resetCamera()
+
+	p.stats.Reset()
+
+	p.points = 0
+	p.pops = 0
+	p.scoringPops = 0
+	p.crashes = 0
+	p.penalty = 0
+	p.respawnPending = false
+	p.impactStrength = 0.0
+	p.strongestImpact = 0.0
+}
+
+func (p *Player) resetCamera() {
+	p.cameraNode.SetPosition(dprec.Vec3Sum(
+		p.Airplane.Body.Position(),
+		dprec.NewVec3(0.0, 50.0, -cameraDistance),
+	))
+	p.cameraNode.ApplyToTarget(false)
+	p.followCamera.AnchorPosition = dprec.Vec3Sum(p.Airplane.Body.Position(), dprec.NewVec3(0.0, 2.0, -cameraDistance))
+}
+
+// Gamepad returns the slot of the gamepad that controls the player, if
+// one does.
+func (p *Player) Gamepad() (int, bool) {
+	return p.gamepad, p.gamepadController != nil
+}
+
+// Stats returns the statistics of the current game of the player.
+func (p *Player) Stats() RunStats {
+	stats := p.stats.Stats()
+	status := p.controller.status()
+	stats.Time = p.controller.gameTime + p.penalty
+	stats.Score = p.points
+	stats.Share = p.points
+	stats.Pops = p.pops
+	if target, ok := p.controller.mode.(TargetGameMode); ok {
+		// The target is the same for all players, so they count towards
+		// it together.
+		stats.Score = status.Score
+		stats.RequiredScore = target.RequiredScore(status)
+	}
+	return stats
+}
+
+// Feedback returns the tactile feedback of the player. The device that
+// produces it follows the input of the player.
+func (p *Player) Feedback() *HapticFeedback {
+	return p.feedback
+}
+
+// AwaitingDevice returns whether the gamepad of the player was
+// disconnected and the player has not picked another device yet.
+func (p *Player) AwaitingDevice() bool {
+	return p.awaitingDevice
+}
+
+// HasKeyboard returns whether the player can steer with the keyboard,
+// which is not the case when another player already uses it.
+func (p *Player) HasKeyboard() bool {
+	return p.keyboardController != nil
+}
+
+func (p *Player) useGamepad(index int, gamepad app.Gamepad) {
+	p.gamepad = index
+	p.gamepadController = NewAirplaneGamepadController(p.Airplane, gamepad)
+	p.feedback.SetHaptics(NewGamepadHaptics(gamepad))
+	p.awaitingDevice = false
+	if p.Input != PlayerInputAuto {
+		p.Input = PlayerInputGamepad(index)
+	}
+}
+
+func (p *Player) useKeyboard() {
+	p.gamepadController = nil
+	p.feedback.SetHaptics(NopHaptics{})
+	if p.keyboardController == nil {
+		p.keyboardController = NewAirplaneKeyboardController(p.Airplane)
+	}
+	if p.mouseController == nil && p.controller.controls.MouseFlight {
+		p.mouseController = NewAirplaneMouseController(p.Airplane, p.cameraNode, p.controller.controls)
+	}
+	p.awaitingDevice = false
+	if p.Input != PlayerInputAuto {
+		p.Input = PlayerInputKeyboard
+	}
+}
+
+func (p *Player) updateInput(elapsedSeconds float64) {
+	// The keyboard remains available to a player with a gamepad, but only
+	// steers while the gamepad is gone, so the two do not fight. The mouse
+	// takes over steering from the keyboard when mouse flight is enabled.
+	switch {
+	case p.gamepadController != nil:
+		p.gamepadController.Update(elapsedSeconds)
+	case p.mouseController != nil:
+		p.mouseController.Update(elapsedSeconds)
+	case p.keyboardController != nil:
+		p.keyboardController.Update(elapsedSeconds)
+	}
+}
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"github.com/mokiat/gomath/dprec"
+	"github.com/mokiat/lacking/game/physics"
+)
+
+// newBodySnapshot records the current motion state of the specified bodies
+// so that it can be restored later on.
+func newBodySnapshot(bodies ...physics.Body) bodySnapshot {
+	states := make([]bodyState, len(bodies))
+	for i, body := range bodies {
+		states[i] = bodyState{
+			position:        body.Position(),
+			rotation:        body.Rotation(),
+			velocity:        body.Velocity(),
+			angularVelocity: body.AngularVelocity(),
+		}
+	}
+	return bodySnapshot{
+		bodies: bodies,
+		states: states,
+	}
+}
+
+type bodySnapshot struct {
+	bodies []physics.Body
+	states []bodyState
+}
+
+func (s bodySnapshot) Restore() {
+	for i, body := range s.bodies {
+		state := s.states[i]
+		body.SetPosition(state.position)
+		body.SetRotation(state.rotation)
+		body.SetVelocity(state.velocity)
+		body.SetAngularVelocity(state.angularVelocity)
+	}
+}
+
+type bodyState struct {
+	position        dprec.Vec3
+	rotation        dprec.Quat
+	velocity        dprec.Vec3
+	angularVelocity dprec.Vec3
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/mokiat/ggj2024/internal/game/data"
+	"github.com/mokiat/gomath/dprec"
+)
+
+const (
+	spawnRayTop            = 1000.0
+	spawnRayBottom         = -1000.0
+	spawnAttemptsPerCow    = 30
+	spawnSampleAttempts    = 100
+	spawnMinSurfaceUpright = 0.7
+)
+
+var errSpawnZoneEmpty = errors.New("spawn zone has no area to sample")
+
+// spawnZonePositions places up to zone.Count points on the terrain surface
+// within the zone. Fewer points are returned if the zone is too crowded
+// or too steep to fit them all.
+func spawnZonePositions(zone *data.SpawnZone, center dprec.Vec3, terrain *data.Terrain, random *rand.Rand, taken []dprec.Vec3) ([]dprec.Vec3, error) {
+	var result []dprec.Vec3
+	for attempt := 0; attempt < zone.Count*spawnAttemptsPerCow && len(result) < zone.Count; attempt++ {
+		x, z, err := spawnZoneSample(zone, center, random)
+		if err != nil {
+			return result, err
+		}
+		position, ok := terrain.RayCast(
+			dprec.NewVec3(x, spawnRayTop, z),
+			dprec.NewVec3(x, spawnRayBottom, z),
+		)
+		if !ok || !isFlat(terrain, position) {
+			continue
+		}
+		if isCrowded(position, taken, zone.MinSpacing) || isCrowded(position, result, zone.MinSpacing) {
+			continue
+		}
+		result = append(result, position)
+	}
+	return result, nil
+}
+
+// spawnZoneSample picks a random point in the XZ plane within the zone.
+// Polygons are sampled by rejection, which is given up on after a number
+// of misses, since a degenerate polygon would never be hit.
+func spawnZoneSample(zone *data.SpawnZone, center dprec.Vec3, random *rand.Rand) (float64, float64, error) {
+	if zone.UsesPolygon() {
+		minimum, maximum := zone.PolygonBounds()
+		for attempt := 0; attempt < spawnSampleAttempts; attempt++ {
+			point := dprec.NewVec2(
+				dprec.Mix(minimum.X, maximum.X, random.Float64()),
+				dprec.Mix(minimum.Y, maximum.Y, random.Float64()),
+			)
+			if zone.PolygonContains(point) {
+				return point.X, point.Y, nil
+			}
+		}
+		return 0.0, 0.0, errSpawnZoneEmpty
+	}
+	// The square root keeps the points evenly spread across the disc
+	// instead of bunching up at the centre.
+	distance := zone.Radius * dprec.Sqrt(random.Float64())
+	angle := dprec.Degrees(random.Float64() * 360.0)
+	return center.X + distance*dprec.Cos(angle), center.Z + distance*dprec.Sin(angle), nil
+}
+
+// isFlat checks that the surface around the position is flat enough
+// for a cow to stand on, by probing the terrain a little to the side.
+func isFlat(terrain *data.Terrain, position dprec.Vec3) bool {
+	const probeOffset = 1.0
+	probe := func(dx, dz float64) (dprec.Vec3, bool) {
+		return terrain.RayCast(
+			dprec.NewVec3(position.X+dx, spawnRayTop, position.Z+dz),
+			dprec.NewVec3(position.X+dx, spawnRayBottom, position.Z+dz),
+		)
+	}
+	east, ok := probe(probeOffset, 0.0)
+	if !ok {
+		return false
+	}
+	south, ok := probe(0.0, probeOffset)
+	if !ok {
+		return false
+	}
+	normal := dprec.UnitVec3(dprec.Vec3Cross(
+		dprec.Vec3Diff(south, position),
+		dprec.Vec3Diff(east, position),
+	))
+	return normal.Y >= spawnMinSurfaceUpright
+}
+
+func isCrowded(position dprec.Vec3, others []dprec.Vec3, spacing float64) bool {
+	for _, other := range others {
+		if dprec.Vec3Diff(position, other).Length() < spacing {
+			return true
+		}
+	}
+	return false
+}
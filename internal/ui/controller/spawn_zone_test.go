@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/mokiat/ggj2024/internal/game/data"
+	"github.com/mokiat/gomath/dprec"
+)
+
+func TestSpawnZoneSamplePolygon(t *testing.T) {
+	zone := &data.SpawnZone{
+		Polygon: []dprec.Vec2{
+			dprec.NewVec2(0.0, 0.0),
+			dprec.NewVec2(10.0, 0.0),
+			dprec.NewVec2(0.0, 10.0),
+		},
+	}
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		x, z, err := spawnZoneSample(zone, dprec.ZeroVec3(), random)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !zone.PolygonContains(dprec.NewVec2(x, z)) {
+			t.Fatalf("sample (%f, %f) is outside the polygon", x, z)
+		}
+	}
+}
+
+func TestSpawnZoneSampleCircle(t *testing.T) {
+	zone := &data.SpawnZone{
+		Radius: 5.0,
+	}
+	center := dprec.NewVec3(100.0, 0.0, -50.0)
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		x, z, err := spawnZoneSample(zone, center, random)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if distance := dprec.NewVec2(x-center.X, z-center.Z).Length(); distance > zone.Radius {
+			t.Fatalf("sample is %f away from the centre", distance)
+		}
+	}
+}
+
+func TestSpawnZoneSampleDegeneratePolygon(t *testing.T) {
+	zone := &data.SpawnZone{
+		Polygon: []dprec.Vec2{
+			dprec.NewVec2(0.0, 0.0),
+			dprec.NewVec2(5.0, 5.0),
+			dprec.NewVec2(10.0, 10.0),
+		},
+	}
+	random := rand.New(rand.NewSource(1))
+	if _, _, err := spawnZoneSample(zone, dprec.ZeroVec3(), random); !errors.Is(err, errSpawnZoneEmpty) {
+		t.Fatalf("expected empty zone error, got %v", err)
+	}
+}
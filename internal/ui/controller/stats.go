@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/mokiat/gomath/dprec"
+)
+
+const (
+	pathSampleInterval = 250 * time.Millisecond
+	maxPathSamples     = 1024
+
+	// maxFlightStep is the distance above which a move between two
+	// updates is considered a respawn and not flight.
+	maxFlightStep = 50.0
+)
+
+// RunStats is a summary of how a player flew during a game.
+type RunStats struct {
+	Time time.Duration
+
+	// Score is the number of points collected and RequiredScore is the
+	// number needed to win, or zero if the game mode has no such target.
+	// When the players share a target, Score is that of the whole team
+	// and Share is the part that the player collected.
+	Score         int
+	Share         int
+	RequiredScore int
+	Pops          int
+
+	MaxSpeed    float64
+	MaxAltitude float64
+	Distance    float64
+	Rubs        int
+	Crashes     int
+
+	// Path holds the horizontal (XZ) positions of the airplane, sampled
+	// at regular intervals.
+	Path []dprec.Vec2
+}
+
+func NewStatsRecorder(airplane *Airplane) *StatsRecorder {
+	return &StatsRecorder{
+		airplane: airplane,
+	}
+}
+
+// StatsRecorder collects the RunStats of an airplane while it flies.
+type StatsRecorder struct {
+	airplane *Airplane
+	stats    RunStats
+
+	lastPosition  dprec.Vec3
+	sampleElapsed time.Duration
+	started       bool
+}
+
+func (r *StatsRecorder) Update(elapsedTime time.Duration) {
+	position := r.airplane.Body.Position()
+	speed := r.airplane.Body.Velocity().Length()
+	r.stats.MaxSpeed = max(r.stats.MaxSpeed, speed)
+	if !r.started {
+		r.started = true
+		r.stats.MaxAltitude = position.Y
+		r.lastPosition = position
+		r.addSample(position)
+		return
+	}
+	r.stats.MaxAltitude = max(r.stats.MaxAltitude, position.Y)
+	if step := dprec.Vec3Diff(position, r.lastPosition).Length(); step < maxFlightStep {
+		r.stats.Distance += step
+	}
+	r.lastPosition = position
+
+	r.sampleElapsed += elapsedTime
+	if r.sampleElapsed >= pathSampleInterval {
+		r.sampleElapsed = 0
+		r.addSample(position)
+	}
+}
+
+func (r *StatsRecorder) RecordRub() {
+	r.stats.Rubs++
+}
+
+func (r *StatsRecorder) RecordCrash() {
+	r.stats.Crashes++
+}
+
+// Stats returns what was recorded so far.
+func (r *StatsRecorder) Stats() RunStats {
+	result := r.stats
+	result.Path = append([]dprec.Vec2(nil), r.stats.Path...)
+	return result
+}
+
+func (r *StatsRecorder) Reset() {
+	r.stats = RunStats{}
+	r.sampleElapsed = 0
+	r.started = false
+}
+
+func (r *StatsRecorder) addSample(position dprec.Vec3) {
+	// Long flights keep every other sample, so that the path covers the
+	// whole flight in bounded memory.
+	if len(r.stats.Path) >= maxPathSamples {
+		kept := r.stats.Path[:0]
+		for i := 0; i < len(r.stats.Path); i += 2 {
+			kept = append(kept, r.stats.Path[i])
+		}
+		r.stats.Path = kept
+	}
+	r.stats.Path = append(r.stats.Path, dprec.NewVec2(position.X, position.Z))
+}
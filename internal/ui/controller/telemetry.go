@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/mokiat/ggj2024/internal/game/data"
+	"github.com/mokiat/lacking/debug/log"
+)
+
+func NewTelemetryRecorder(writer data.TelemetryWriter) *TelemetryRecorder {
+	return &TelemetryRecorder{
+		writer: writer,
+	}
+}
+
+// TelemetryRecorder turns the state of airplanes into telemetry samples.
+// It does not depend on a PlayController, so it can be used with airplanes
+// that are simulated without a window.
+type TelemetryRecorder struct {
+	writer data.TelemetryWriter
+	step   int
+	time   time.Duration
+}
+
+// Record writes one sample for each of the specified airplanes and their
+// balls, which are matched by index. Balls can be nil. It is meant to be
+// called after each physics step, where elapsedTime is the duration of
+// the step.
+func (r *TelemetryRecorder) Record(elapsedTime time.Duration, airplanes []*Airplane, balls []*Ball) error {
+	r.step++
+	r.time += elapsedTime
+	for i, airplane := range airplanes {
+		body := airplane.Body
+		sample := data.TelemetrySample{
+			Step:            r.step,
+			Time:            r.time.Seconds(),
+			Player:          i,
+			Position:        body.Position(),
+			Velocity:        body.Velocity(),
+			Rotation:        body.Rotation(),
+			AngularVelocity: body.AngularVelocity(),
+			Aileron:         airplane.AileronAngle.Degrees(),
+			Elevator:        airplane.ElevatorAngle.Degrees(),
+			Rudder:          airplane.RudderAngle.Degrees(),
+			Thrust:          airplane.Thrust,
+			TargetThrust:    airplane.TargetThrust,
+		}
+		if i < len(balls) && balls[i] != nil {
+			sample.BallPosition = balls[i].Body.Position()
+		}
+		if err := r.writer.Write(sample); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TelemetryRecorder) Close() error {
+	return r.writer.Close()
+}
+
+// StartTelemetry writes a sample for every airplane after each simulation
+// step until StopTelemetry is called. Any previous recording is stopped.
+func (c *PlayController) StartTelemetry(writer data.TelemetryWriter) {
+	if err := c.StopTelemetry(); err != nil {
+		log.Warn("Failed to finish telemetry: %v", err)
+	}
+	c.telemetry = NewTelemetryRecorder(writer)
+	c.telemetry.time = c.gameTime
+	// The scene updates once per frame, while the physics can take several
+	// steps in that time, so samples are taken from the physics scene.
+	c.telemetrySubscription = c.physicsScene.SubscribePostUpdate(c.recordTelemetry)
+}
+
+// StopTelemetry finishes the current recording, if there is one.
+func (c *PlayController) StopTelemetry() error {
+	if c.telemetry == nil {
+		return nil
+	}
+	c.telemetrySubscription.Delete()
+	c.telemetrySubscription = nil
+	err := c.telemetry.Close()
+	c.telemetry = nil
+	return err
+}
+
+// TelemetryActive returns whether telemetry is being recorded.
+func (c *PlayController) TelemetryActive() bool {
+	return c.telemetry != nil
+}
+
+func (c *PlayController) recordTelemetry(elapsedTime time.Duration) {
+	if c.telemetry == nil {
+		return
+	}
+	airplanes := make([]*Airplane, len(c.players))
+	balls := make([]*Ball, len(c.players))
+	for i, player := range c.players {
+		airplanes[i] = player.Airplane
+		balls[i] = player.Ball
+	}
+	if err := c.telemetry.Record(elapsedTime, airplanes, balls); err != nil {
+		log.Error("Failed to record telemetry: %v", err)
+		if err := c.StopTelemetry(); err != nil {
+			log.Warn("Failed to finish telemetry: %v", err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mokiat/ggj2024/internal/game/data"
+	"github.com/mokiat/gomath/dprec"
+	"github.com/mokiat/lacking/game/ecs"
+	"github.com/mokiat/lacking/game/hierarchy"
+	"github.com/mokiat/lacking/game/physics"
+)
+
+const testPhysicsInterval = 16 * time.Millisecond
+
+// testAirplaneModel is a node hierarchy in place of the airplane model,
+// so that the airplane can be flown without graphics.
+type testAirplaneModel struct {
+	root *hierarchy.Node
+}
+
+func newTestAirplaneModel() *testAirplaneModel {
+	root := hierarchy.NewNode()
+	for name, position := range map[string]dprec.Vec3{
+		"Body":         dprec.ZeroVec3(),
+		"LeftAileron":  dprec.NewVec3(3.0, 0.0, -1.0),
+		"RightAileron": dprec.NewVec3(-3.0, 0.0, -1.0),
+		"Elevators":    dprec.NewVec3(0.0, 0.5, -6.0),
+		"Rudder":       dprec.NewVec3(0.0, 1.0, -6.0),
+		"Propeller":    dprec.NewVec3(0.0, 0.0, 2.0),
+	} {
+		node := hierarchy.NewNode()
+		node.SetName(name)
+		node.SetPosition(position)
+		root.AppendChild(node)
+	}
+	return &testAirplaneModel{
+		root: root,
+	}
+}
+
+func (m *testAirplaneModel) Root() *hierarchy.Node {
+	return m.root
+}
+
+func (m *testAirplaneModel) FindNode(name string) *hierarchy.Node {
+	return m.root.FindNode(name)
+}
+
+func TestTelemetryHeadlessFlight(t *testing.T) {
+	physicsScene := physics.NewEngine(testPhysicsInterval).CreateScene()
+	ecsScene := ecs.NewEngine().CreateScene()
+	airplane := NewAirplane(physicsScene, ecsScene, newTestAirplaneModel(), dprec.NewVec3(0.0, 100.0, 0.0))
+
+	var out bytes.Buffer
+	writer, err := data.NewTelemetryWriter(&out, data.TelemetryFormatJSONLines)
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	recorder := NewTelemetryRecorder(writer)
+
+	physicsScene.SubscribePreUpdate(func(elapsedTime time.Duration) {
+		airplane.UpdatePhysics(elapsedTime.Seconds())
+	})
+	physicsScene.SubscribePostUpdate(func(elapsedTime time.Duration) {
+		if err := recorder.Record(elapsedTime, []*Airplane{airplane}, nil); err != nil {
+			t.Fatalf("failed to record: %v", err)
+		}
+	})
+
+	// Frames are longer than physics steps, as is the case on a slow
+	// machine, so that several steps are taken per frame.
+	const frameCount = 20
+	for i := 0; i < frameCount; i++ {
+		physicsScene.Update(3 * testPhysicsInterval)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("failed to close recorder: %v", err)
+	}
+
+	var samples []data.TelemetrySample
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var sample data.TelemetrySample
+		if err := json.Unmarshal(scanner.Bytes(), &sample); err != nil {
+			t.Fatalf("failed to decode sample: %v", err)
+		}
+		samples = append(samples, sample)
+	}
+	if len(samples) != 3*frameCount {
+		t.Fatalf("expected one sample per physics step (%d), got %d", 3*frameCount, len(samples))
+	}
+	for i, sample := range samples {
+		if sample.Step != i+1 {
+			t.Errorf("sample %d: expected step %d, got %d", i, i+1, sample.Step)
+		}
+		expectedTime := (time.Duration(i+1) * testPhysicsInterval).Seconds()
+		if dprec.Abs(sample.Time-expectedTime) > 1e-9 {
+			t.Errorf("sample %d: expected time %f, got %f", i, expectedTime, sample.Time)
+		}
+	}
+	first, last := samples[0], samples[len(samples)-1]
+	if last.Position.Z <= first.Position.Z {
+		t.Errorf("expected airplane to fly forward, got from %v to %v", first.Position, last.Position)
+	}
+}
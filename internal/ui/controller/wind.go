@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"math/rand"
+
+	"github.com/mokiat/ggj2024/internal/game/data"
+	"github.com/mokiat/gomath/dprec"
+	"github.com/mokiat/lacking/game/physics/solver"
+)
+
+const airDensity = 1.2
+
+var _ solver.Medium = (*WindSystem)(nil)
+
+// NewWindSystem creates a WindSystem that moves the air according to the
+// specified wind settings. It needs to be set as the medium solver of the
+// physics scene, which is what aerodynamic shapes and drag respond to.
+func NewWindSystem(wind data.Wind) *WindSystem {
+	return &WindSystem{
+		wind: wind,
+	}
+}
+
+type WindSystem struct {
+	wind data.Wind
+
+	time        float64
+	gust        float64
+	gustFrom    float64
+	gustTo      float64
+	gustElapsed float64
+}
+
+func (s *WindSystem) Reset() {
+	s.time = 0.0
+	s.gust = 0.0
+	s.gustFrom = 0.0
+	s.gustTo = 0.0
+	s.gustElapsed = 0.0
+}
+
+func (s *WindSystem) Update(elapsedSeconds float64) {
+	s.time += elapsedSeconds
+
+	interval := s.wind.GustInterval.Seconds()
+	if interval <= 0.0 {
+		return
+	}
+	s.gustElapsed += elapsedSeconds
+	if s.gustElapsed >= interval {
+		s.gustElapsed -= interval
+		s.gustFrom = s.gustTo
+		s.gustTo = rand.Float64() * s.wind.GustStrength
+	}
+	progress := s.gustElapsed / interval
+	// Smoothstep, so that gusts build up and die down gradually.
+	progress = progress * progress * (3.0 - 2.0*progress)
+	s.gust = dprec.Mix(s.gustFrom, s.gustTo, progress)
+}
+
+// Wind returns the steady wind together with the current gust, ignoring
+// any local turbulence.
+func (s *WindSystem) Wind() dprec.Vec3 {
+	velocity := s.wind.Velocity
+	if velocity.IsZero() {
+		return velocity
+	}
+	return dprec.Vec3Sum(velocity, dprec.ResizedVec3(velocity, s.gust))
+}
+
+func (s *WindSystem) Density(position dprec.Vec3) float64 {
+	return airDensity
+}
+
+func (s *WindSystem) Velocity(position dprec.Vec3) dprec.Vec3 {
+	result := s.Wind()
+	for _, zone := range s.wind.TurbulenceZones {
+		result = dprec.Vec3Sum(result, s.turbulence(zone, position))
+	}
+	return result
+}
+
+func (s *WindSystem) turbulence(zone data.TurbulenceZone, position dprec.Vec3) dprec.Vec3 {
+	delta := dprec.Vec3Diff(position, zone.Center)
+	distance := dprec.Sqrt(delta.X*delta.X + delta.Z*delta.Z)
+	if distance >= zone.Radius || delta.Y < 0.0 || delta.Y >= zone.Height {
+		return dprec.ZeroVec3()
+	}
+	falloff := (1.0 - distance/zone.Radius) * (1.0 - delta.Y/zone.Height)
+
+	// A few overlapping waves with unrelated frequencies give air that
+	// feels chaotic but stays smooth in both space and time.
+	t := s.time
+	return dprec.Vec3Prod(dprec.NewVec3(
+		dprec.Sin(dprec.Radians(0.11*position.Z+1.7*t))+0.5*dprec.Sin(dprec.Radians(0.23*position.Y+2.9*t)),
+		dprec.Sin(dprec.Radians(0.13*position.X+2.3*t))+0.5*dprec.Sin(dprec.Radians(0.19*position.Z+3.7*t)),
+		dprec.Sin(dprec.Radians(0.17*position.X+1.3*t))+0.5*dprec.Sin(dprec.Radians(0.29*position.Y+3.1*t)),
+	), zone.Strength*falloff/1.5)
+}
@@ -1,12 +1,23 @@
 package global
 
 import (
+	"github.com/mokiat/ggj2024/internal/game/data"
+	"github.com/mokiat/ggj2024/internal/game/render"
 	"github.com/mokiat/lacking/audio"
 	"github.com/mokiat/lacking/game"
+	co "github.com/mokiat/lacking/ui/component"
 )
 
 type Context struct {
 	AudioAPI    audio.API
 	Engine      *game.Engine
+	Split       *render.SplitController
 	ResourceSet *game.ResourceSet
+	PlayCache   *data.PlayCache
+}
+
+// AppScope is the scope of the whole application. Unlike the scopes of
+// screens and overlays, it remains valid for as long as the game runs.
+type AppScope struct {
+	co.Scope
 }
@@ -4,8 +4,11 @@ import "github.com/mokiat/lacking/ui/mvc"
 
 const (
 	ViewNameIntro   ViewName = "intro"
+	ViewNameMenu    ViewName = "menu"
 	ViewNameLoading ViewName = "loading"
 	ViewNamePlay    ViewName = "play"
+
+	ViewNameAchievements ViewName = "achievements"
 )
 
 type ViewName = string
@@ -16,6 +16,7 @@ type Loading struct {
 	eventBus     *mvc.EventBus
 	promise      LoadingPromise
 	nextViewName ViewName
+	retryFunc    func()
 }
 
 func (l *Loading) Promise() LoadingPromise {
@@ -24,6 +25,9 @@ func (l *Loading) Promise() LoadingPromise {
 
 func (l *Loading) SetPromise(promise LoadingPromise) {
 	l.promise = promise
+	l.eventBus.Notify(&LoadingPromiseSetEvent{
+		Promise: promise,
+	})
 }
 
 func (l *Loading) NextViewName() ViewName {
@@ -33,3 +37,17 @@ func (l *Loading) NextViewName() ViewName {
 func (l *Loading) SetNextViewName(name ViewName) {
 	l.nextViewName = name
 }
+
+// RetryFunc returns the function that restarts the loading process, should
+// the current one fail.
+func (l *Loading) RetryFunc() func() {
+	return l.retryFunc
+}
+
+func (l *Loading) SetRetryFunc(retryFunc func()) {
+	l.retryFunc = retryFunc
+}
+
+type LoadingPromiseSetEvent struct {
+	Promise LoadingPromise
+}
@@ -1,29 +1,206 @@
 package model
 
 import (
-	"errors"
-
 	"github.com/mokiat/ggj2024/internal/game/data"
+	"github.com/mokiat/ggj2024/internal/game/netplay"
+	"github.com/mokiat/lacking/debug/log"
 	"github.com/mokiat/lacking/ui/mvc"
 	"github.com/mokiat/lacking/util/async"
 )
 
+const DefaultNetworkAddress = "127.0.0.1:7777"
+
+const (
+	PlayStateNotScheduled PlayState = iota
+	PlayStateLoading
+	PlayStateReady
+	PlayStateFailed
+)
+
+// PlayState indicates where the play data is in its loading lifecycle.
+type PlayState int
+
 func NewPlay(eventBus *mvc.EventBus) *Play {
 	return &Play{
 		eventBus: eventBus,
-		promise:  async.NewFailedPromise[*data.PlayData](errors.New("not scheduled")),
+		state:    PlayStateNotScheduled,
+
+		networkAddress: DefaultNetworkAddress,
 	}
 }
 
+// Play holds the data needed to start a play session. The data is only
+// ever read from the loading promise by the model itself and is handed
+// over to the UI thread through the dispatch function.
 type Play struct {
 	eventBus *mvc.EventBus
-	promise  async.Promise[*data.PlayData]
+
+	state     PlayState
+	levelName string
+	gameMode  string
+	data      *data.PlayData
+	err       error
+	progress  *data.LoadProgress
+
+	generation int
+
+	networkAddress string
+	server         *netplay.Server
+	client         *netplay.Client
+}
+
+func (p *Play) State() PlayState {
+	return p.state
+}
+
+// LevelName returns the name of the level that was last scheduled for
+// loading or an empty string if none was.
+func (p *Play) LevelName() string {
+	return p.levelName
+}
+
+// GameMode returns the name of the game mode that the player picked.
+func (p *Play) GameMode() string {
+	return p.gameMode
+}
+
+func (p *Play) SetGameMode(gameMode string) {
+	p.gameMode = gameMode
+}
+
+// NetworkAddress returns the address that was last used to host or join
+// a network game.
+func (p *Play) NetworkAddress() string {
+	return p.networkAddress
+}
+
+func (p *Play) SetNetworkAddress(address string) {
+	p.networkAddress = address
+}
+
+// SetServer makes the next play session host a network game on the
+// specified server. Any previous network session is closed.
+func (p *Play) SetServer(server *netplay.Server) {
+	p.CloseNetwork()
+	p.server = server
+}
+
+// SetClient makes the next play session join a network game through the
+// specified client. Any previous network session is closed.
+func (p *Play) SetClient(client *netplay.Client) {
+	p.CloseNetwork()
+	p.client = client
+}
+
+// TakeNetwork hands over the network session of the next play session,
+// if there is one. The caller becomes responsible for closing it.
+func (p *Play) TakeNetwork() (*netplay.Server, *netplay.Client) {
+	server, client := p.server, p.client
+	p.server, p.client = nil, nil
+	return server, client
 }
 
-func (h *Play) DataPromise() async.Promise[*data.PlayData] {
-	return h.promise
+// CloseNetwork closes any network session that was not taken.
+func (p *Play) CloseNetwork() {
+	server, client := p.TakeNetwork()
+	if server != nil {
+		if err := server.Close(); err != nil {
+			log.Warn("Failed to close server: %v", err)
+		}
+	}
+	if client != nil {
+		if err := client.Close(); err != nil {
+			log.Warn("Failed to close client: %v", err)
+		}
+	}
+}
+
+// Data returns the loaded play data or nil if the data is not ready.
+func (p *Play) Data() *data.PlayData {
+	return p.data
+}
+
+// Err returns the reason why loading failed, if it did.
+func (p *Play) Err() error {
+	return p.err
+}
+
+// Load starts tracking the specified promise. Any previous load is
+// abandoned. The dispatch function must run the callback on the UI thread.
+func (p *Play) Load(levelName string, promise async.Promise[*data.PlayData], progress *data.LoadProgress, dispatch func(func())) {
+	p.generation++
+	generation := p.generation
+	p.levelName = levelName
+	p.progress = progress
+	p.setState(PlayStateLoading, nil, nil)
+
+	go func() {
+		playData, err := promise.Wait()
+		dispatch(func() {
+			if generation != p.generation {
+				return // superseded by a newer load
+			}
+			if err != nil {
+				p.setState(PlayStateFailed, nil, err)
+			} else {
+				p.setState(PlayStateReady, playData, nil)
+			}
+		})
+	}()
+}
+
+// LoadingPromise returns a LoadingPromise that tracks the current load.
+// A PlayStateChangedEvent is published when the load finishes.
+func (p *Play) LoadingPromise() LoadingPromise {
+	return &playLoadingPromise{
+		play:       p,
+		generation: p.generation,
+	}
+}
+
+func (p *Play) setState(state PlayState, playData *data.PlayData, err error) {
+	p.state = state
+	p.data = playData
+	p.err = err
+	p.eventBus.Notify(&PlayStateChangedEvent{
+		State: state,
+	})
+}
+
+type PlayStateChangedEvent struct {
+	State PlayState
+}
+
+type playLoadingPromise struct {
+	play       *Play
+	generation int
+}
+
+func (p *playLoadingPromise) Ready() bool {
+	return p.isCurrent() && p.play.state == PlayStateReady
+}
+
+func (p *playLoadingPromise) Progress() float64 {
+	if !p.isCurrent() {
+		return 0.0
+	}
+	switch p.play.state {
+	case PlayStateLoading:
+		return p.play.progress.Ratio()
+	case PlayStateReady:
+		return 1.0
+	default:
+		return 0.0
+	}
+}
+
+func (p *playLoadingPromise) Err() error {
+	if !p.isCurrent() {
+		return nil
+	}
+	return p.play.err
 }
 
-func (h *Play) SetDataPromise(promise async.Promise[*data.PlayData]) {
-	h.promise = promise
+func (p *playLoadingPromise) isCurrent() bool {
+	return p.play.generation == p.generation
 }
@@ -0,0 +1,142 @@
+package view
+
+import (
+	"fmt"
+
+	"github.com/mokiat/ggj2024/internal/game/data"
+	"github.com/mokiat/ggj2024/internal/ui/model"
+	"github.com/mokiat/gog/opt"
+	"github.com/mokiat/lacking/debug/log"
+	"github.com/mokiat/lacking/ui"
+	co "github.com/mokiat/lacking/ui/component"
+	"github.com/mokiat/lacking/ui/layout"
+	"github.com/mokiat/lacking/ui/std"
+)
+
+var AchievementsScreen = co.Define(&achievementsScreenComponent{})
+
+type AchievementsScreenData struct {
+	AppModel *model.Application
+}
+
+var _ ui.ElementKeyboardHandler = (*achievementsScreenComponent)(nil)
+
+type achievementsScreenComponent struct {
+	co.BaseComponent
+
+	appModel *model.Application
+	records  map[string]data.AchievementRecord
+}
+
+func (c *achievementsScreenComponent) OnCreate() {
+	screenData := co.GetData[AchievementsScreenData](c.Properties())
+	c.appModel = screenData.AppModel
+
+	records, err := data.LoadAchievementRecords()
+	if err != nil {
+		log.Warn("Failed to load achievements: %v", err)
+	}
+	c.records = records
+}
+
+func (c *achievementsScreenComponent) Render() co.Instance {
+	return co.New(std.Container, func() {
+		co.WithData(std.ContainerData{
+			BackgroundColor: opt.V(ui.Black()),
+			Layout:          layout.Anchor(),
+		})
+
+		co.WithChild("content", co.New(std.Element, func() {
+			co.WithLayoutData(layout.Data{
+				HorizontalCenter: opt.V(0),
+				VerticalCenter:   opt.V(0),
+			})
+			co.WithData(std.ElementData{
+				Essence:   c,
+				Focusable: opt.V(true),
+				Focused:   opt.V(true),
+				Layout: layout.Vertical(layout.VerticalSettings{
+					ContentAlignment: layout.HorizontalAlignmentCenter,
+					ContentSpacing:   20,
+				}),
+			})
+
+			co.WithChild("title", co.New(std.Label, func() {
+				co.WithData(std.LabelData{
+					Font:      co.OpenFont(c.Scope(), "ui:///roboto-bold.ttf"),
+					Text:      fmt.Sprintf("Achievements %d/%d", len(c.records), len(data.Achievements)),
+					FontSize:  opt.V(float32(32)),
+					FontColor: opt.V(ui.White()),
+				})
+			}))
+
+			for _, achievement := range data.Achievements {
+				achievement := achievement
+				co.WithChild(achievement.ID, c.renderAchievement(achievement))
+			}
+
+			co.WithChild("back", co.New(std.Button, func() {
+				co.WithData(std.ButtonData{
+					Text: "Back",
+				})
+				co.WithCallbackData(std.ButtonCallbackData{
+					OnClick: c.onBack,
+				})
+			}))
+		}))
+	})
+}
+
+func (c *achievementsScreenComponent) renderAchievement(achievement data.Achievement) co.Instance {
+	record, unlocked := c.records[achievement.ID]
+	titleColor := ui.RGB(0x80, 0x80, 0x80)
+	status := "Locked"
+	if unlocked {
+		titleColor = ui.White()
+		status = fmt.Sprintf("Unlocked on %s", record.UnlockedAt.Format("2006-01-02"))
+	}
+
+	return co.New(std.Element, func() {
+		co.WithData(std.ElementData{
+			Layout: layout.Vertical(layout.VerticalSettings{
+				ContentAlignment: layout.HorizontalAlignmentCenter,
+				ContentSpacing:   5,
+			}),
+		})
+
+		co.WithChild("title", co.New(std.Label, func() {
+			co.WithData(std.LabelData{
+				Font:      co.OpenFont(c.Scope(), "ui:///roboto-bold.ttf"),
+				Text:      achievement.Title,
+				FontSize:  opt.V(float32(24)),
+				FontColor: opt.V(titleColor),
+			})
+		}))
+
+		co.WithChild("description", co.New(std.Label, func() {
+			co.WithData(std.LabelData{
+				Font:      co.OpenFont(c.Scope(), "ui:///roboto-regular.ttf"),
+				Text:      fmt.Sprintf("%s %s", achievement.Description, status),
+				FontSize:  opt.V(float32(18)),
+				FontColor: opt.V(ui.RGB(0xD9, 0xAD, 0x6C)),
+			})
+		}))
+	})
+}
+
+func (c *achievementsScreenComponent) OnKeyboardEvent(element *ui.Element, event ui.KeyboardEvent) bool {
+	if event.Action != ui.KeyboardActionDown {
+		return false
+	}
+	switch event.Code {
+	case ui.KeyCodeEscape, ui.KeyCodeEnter, ui.KeyCodeSpace:
+		c.onBack()
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *achievementsScreenComponent) onBack() {
+	c.appModel.SetActiveView(model.ViewNameMenu)
+}
@@ -44,6 +44,14 @@ func (c *applicationComponent) Render() co.Instance {
 			})
 		}))
 
+		co.WithChild(model.ViewNameMenu, co.New(MenuScreen, func() {
+			co.WithData(MenuScreenData{
+				AppModel:     c.appModel,
+				LoadingModel: c.loadingModel,
+				PlayModel:    c.playModel,
+			})
+		}))
+
 		co.WithChild(model.ViewNameLoading, co.New(LoadingScreen, func() {
 			co.WithData(LoadingScreenData{
 				AppModel:     c.appModel,
@@ -58,6 +66,12 @@ func (c *applicationComponent) Render() co.Instance {
 				PlayModel:    c.playModel,
 			})
 		}))
+
+		co.WithChild(model.ViewNameAchievements, co.New(AchievementsScreen, func() {
+			co.WithData(AchievementsScreenData{
+				AppModel: c.appModel,
+			})
+		}))
 	})
 }
 
@@ -0,0 +1,157 @@
+package view
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mokiat/ggj2024/internal/ui/controller"
+	"github.com/mokiat/ggj2024/internal/ui/widget"
+	"github.com/mokiat/gog/opt"
+	"github.com/mokiat/lacking/ui"
+	co "github.com/mokiat/lacking/ui/component"
+	"github.com/mokiat/lacking/ui/layout"
+	"github.com/mokiat/lacking/ui/std"
+)
+
+const devicePollInterval = 100 * time.Millisecond
+
+var DisconnectScreen = co.Define(&disconnectScreenComponent{})
+
+type DisconnectScreenData struct {
+	Controller *controller.PlayController
+	Player     *controller.Player
+}
+
+type DisconnectScreenCallbackData struct {
+	OnResume func()
+}
+
+var _ ui.ElementKeyboardHandler = (*disconnectScreenComponent)(nil)
+
+type disconnectScreenComponent struct {
+	co.BaseComponent
+
+	controller *controller.PlayController
+	player     *controller.Player
+	onResume   func()
+
+	resumed bool
+}
+
+func (c *disconnectScreenComponent) OnCreate() {
+	data := co.GetData[DisconnectScreenData](c.Properties())
+	c.controller = data.Controller
+	c.player = data.Player
+	callbackData := co.GetCallbackData[DisconnectScreenCallbackData](c.Properties())
+	c.onResume = callbackData.OnResume
+
+	// The game is paused, so devices need to be checked from here.
+	co.Every(c.Scope(), devicePollInterval, func() {
+		if c.resumed {
+			return
+		}
+		c.controller.UpdateDevices()
+		if !c.controller.AwaitingDevice() {
+			c.resume()
+		}
+	})
+}
+
+func (c *disconnectScreenComponent) Render() co.Instance {
+	return co.New(widget.Modal, func() {
+		co.WithLayoutData(layout.Data{
+			Width:            opt.V(520),
+			Height:           opt.V(200),
+			HorizontalCenter: opt.V(0),
+			VerticalCenter:   opt.V(0),
+		})
+		co.WithChild("frame", co.New(std.Container, func() {
+			co.WithData(std.ContainerData{
+				BackgroundColor: opt.V(ui.RGB(0x40, 0x30, 0x20)),
+				Layout: layout.Vertical(layout.VerticalSettings{
+					ContentAlignment: layout.HorizontalAlignmentCenter,
+					ContentSpacing:   15,
+				}),
+			})
+
+			co.WithChild("title", co.New(std.Label, func() {
+				co.WithData(std.LabelData{
+					Font:      co.OpenFont(c.Scope(), "ui:///roboto-bold.ttf"),
+					Text:      fmt.Sprintf("Player %d: controller disconnected", c.player.Index+1),
+					FontSize:  opt.V(float32(28)),
+					FontColor: opt.V(ui.White()),
+				})
+			}))
+
+			co.WithChild("hint", co.New(std.Label, func() {
+				co.WithData(std.LabelData{
+					Font:      co.OpenFont(c.Scope(), "ui:///roboto-regular.ttf"),
+					Text:      c.hint(),
+					FontSize:  opt.V(float32(18)),
+					FontColor: opt.V(ui.RGB(0xD9, 0xAD, 0x6C)),
+				})
+			}))
+
+			co.WithChild("actions", co.New(std.Element, func() {
+				co.WithData(std.ElementData{
+					Essence:   c,
+					Focusable: opt.V(true),
+					Focused:   opt.V(true),
+					Layout:    layout.Fill(),
+				})
+
+				// The keyboard can steer only one player, so the game
+				// stays paused until a gamepad is picked when it is taken.
+				if c.player.HasKeyboard() {
+					co.WithChild("keyboard", co.New(std.Button, func() {
+						co.WithData(std.ButtonData{
+							Text: "Continue with Keyboard",
+						})
+						co.WithCallbackData(std.ButtonCallbackData{
+							OnClick: c.onKeyboard,
+						})
+					}))
+				}
+			}))
+		}))
+	})
+}
+
+func (c *disconnectScreenComponent) OnKeyboardEvent(element *ui.Element, event ui.KeyboardEvent) bool {
+	if event.Action != ui.KeyboardActionDown {
+		return false
+	}
+	switch event.Code {
+	case ui.KeyCodeSpace, ui.KeyCodeEnter:
+		c.onKeyboard()
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *disconnectScreenComponent) hint() string {
+	if c.player.HasKeyboard() {
+		return "Reconnect it or press any button on another controller."
+	}
+	return "The keyboard is taken. Reconnect it or press any button on another controller."
+}
+
+func (c *disconnectScreenComponent) onKeyboard() {
+	if !c.player.HasKeyboard() {
+		return
+	}
+	c.controller.ContinueWithKeyboard()
+	if !c.controller.AwaitingDevice() {
+		c.resume()
+	}
+}
+
+func (c *disconnectScreenComponent) resume() {
+	if c.resumed {
+		return
+	}
+	c.resumed = true
+	co.CloseOverlay(c.Scope())
+	c.onResume()
+}
@@ -3,8 +3,6 @@ package view
 import (
 	"time"
 
-	"github.com/mokiat/ggj2024/internal/game/data"
-	"github.com/mokiat/ggj2024/internal/ui/global"
 	"github.com/mokiat/ggj2024/internal/ui/model"
 	"github.com/mokiat/gog/opt"
 	"github.com/mokiat/lacking/ui"
@@ -28,22 +26,13 @@ type introScreenComponent struct {
 func (c *introScreenComponent) OnCreate() {
 	co.Window(c.Scope()).SetCursorVisible(false)
 
-	context := co.TypedValue[global.Context](c.Scope())
-	audioAPI := context.AudioAPI
-	engine := context.Engine
-	resourceSet := context.ResourceSet
-
 	introData := co.GetData[IntroScreenData](c.Properties())
 	appModel := introData.AppModel
-	loadingModel := introData.LoadingModel
 	playModel := introData.PlayModel
-	playModel.SetDataPromise(data.LoadPlayData(audioAPI, engine, resourceSet))
+	schedulePlayData(c.Scope(), playModel)
 
 	co.After(c.Scope(), time.Second, func() {
-		promise := playModel.DataPromise()
-		loadingModel.SetPromise(model.ToLoadingPromise(promise))
-		loadingModel.SetNextViewName(model.ViewNamePlay)
-		appModel.SetActiveView(model.ViewNameLoading)
+		appModel.SetActiveView(model.ViewNameMenu)
 	})
 }
 
@@ -1,14 +1,18 @@
 package view
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/mokiat/ggj2024/internal/game/data"
 	"github.com/mokiat/ggj2024/internal/ui/model"
 	"github.com/mokiat/ggj2024/internal/ui/widget"
 	"github.com/mokiat/gog/opt"
 	"github.com/mokiat/lacking/ui"
 	co "github.com/mokiat/lacking/ui/component"
 	"github.com/mokiat/lacking/ui/layout"
+	"github.com/mokiat/lacking/ui/mvc"
 	"github.com/mokiat/lacking/ui/std"
 )
 
@@ -17,23 +21,22 @@ type LoadingScreenData struct {
 	LoadingModel *model.Loading
 }
 
-var LoadingScreen = co.Define(&loadingScreenComponent{})
+var LoadingScreen = mvc.EventListener(co.Define(&loadingScreenComponent{}))
 
 type loadingScreenComponent struct {
 	co.BaseComponent
+
+	appModel     *model.Application
+	loadingModel *model.Loading
+
+	err error
 }
 
 func (c *loadingScreenComponent) OnCreate() {
 	screenData := co.GetData[LoadingScreenData](c.Properties())
-	appModel := screenData.AppModel
-	loadingModel := screenData.LoadingModel
-	co.After(c.Scope(), time.Millisecond, func() {
-		loadingModel.Promise().OnReady(func() {
-			co.Schedule(c.Scope(), func() {
-				appModel.SetActiveView(loadingModel.NextViewName())
-			})
-		})
-	})
+	c.appModel = screenData.AppModel
+	c.loadingModel = screenData.LoadingModel
+	co.After(c.Scope(), time.Millisecond, c.checkPromise)
 }
 
 func (c *loadingScreenComponent) Render() co.Instance {
@@ -43,6 +46,11 @@ func (c *loadingScreenComponent) Render() co.Instance {
 			Layout:          layout.Anchor(),
 		})
 
+		if c.err != nil {
+			co.WithChild("error", c.renderError())
+			return
+		}
+
 		co.WithChild("loading", co.New(widget.Loading, func() {
 			co.WithLayoutData(layout.Data{
 				HorizontalCenter: opt.V(0),
@@ -50,6 +58,18 @@ func (c *loadingScreenComponent) Render() co.Instance {
 			})
 		}))
 
+		co.WithChild("progress", co.New(widget.ProgressBar, func() {
+			co.WithLayoutData(layout.Data{
+				Width:            opt.V(400),
+				Height:           opt.V(16),
+				HorizontalCenter: opt.V(0),
+				VerticalCenter:   opt.V(180),
+			})
+			co.WithData(widget.ProgressBarData{
+				Provider: c.loadingModel.Promise(),
+			})
+		}))
+
 		co.WithChild("loading-text", co.New(std.Label, func() {
 			co.WithLayoutData(layout.Data{
 				Right:  opt.V(10),
@@ -64,3 +84,107 @@ func (c *loadingScreenComponent) Render() co.Instance {
 		}))
 	})
 }
+
+func (c *loadingScreenComponent) renderError() co.Instance {
+	title := "Failed to load game data"
+	details := c.err.Error()
+	var assetErr *data.AssetError
+	if errors.As(c.err, &assetErr) {
+		title = fmt.Sprintf("Failed to load %s", assetErr.Asset)
+		details = assetErr.Err.Error()
+	}
+
+	return co.New(std.Element, func() {
+		co.WithLayoutData(layout.Data{
+			HorizontalCenter: opt.V(0),
+			VerticalCenter:   opt.V(0),
+		})
+		co.WithData(std.ElementData{
+			Layout: layout.Vertical(layout.VerticalSettings{
+				ContentAlignment: layout.HorizontalAlignmentCenter,
+				ContentSpacing:   20,
+			}),
+		})
+
+		co.WithChild("title", co.New(std.Label, func() {
+			co.WithData(std.LabelData{
+				Font:      co.OpenFont(c.Scope(), "ui:///roboto-bold.ttf"),
+				Text:      title,
+				FontSize:  opt.V(float32(32)),
+				FontColor: opt.V(ui.RGB(0xD9, 0xAD, 0x6C)),
+			})
+		}))
+
+		co.WithChild("details", co.New(std.Label, func() {
+			co.WithData(std.LabelData{
+				Font:      co.OpenFont(c.Scope(), "ui:///roboto-regular.ttf"),
+				Text:      details,
+				FontSize:  opt.V(float32(20)),
+				FontColor: opt.V(ui.White()),
+			})
+		}))
+
+		co.WithChild("actions", co.New(std.Element, func() {
+			co.WithData(std.ElementData{
+				Layout: layout.Horizontal(layout.HorizontalSettings{
+					ContentSpacing: 20,
+				}),
+			})
+
+			co.WithChild("retry", co.New(std.Button, func() {
+				co.WithData(std.ButtonData{
+					Text:    "Retry",
+					Enabled: opt.V(c.loadingModel.RetryFunc() != nil),
+				})
+				co.WithCallbackData(std.ButtonCallbackData{
+					OnClick: c.onRetry,
+				})
+			}))
+
+			co.WithChild("quit", co.New(std.Button, func() {
+				co.WithData(std.ButtonData{
+					Text: "Quit",
+				})
+				co.WithCallbackData(std.ButtonCallbackData{
+					OnClick: c.onQuit,
+				})
+			}))
+		}))
+	})
+}
+
+func (c *loadingScreenComponent) OnEvent(event mvc.Event) {
+	switch event.(type) {
+	case *model.LoadingPromiseSetEvent:
+		c.err = nil
+		c.Invalidate()
+		c.checkPromise()
+	case *model.PlayStateChangedEvent:
+		c.checkPromise()
+	}
+}
+
+func (c *loadingScreenComponent) checkPromise() {
+	promise := c.loadingModel.Promise()
+	if promise == nil {
+		return
+	}
+	if err := promise.Err(); err != nil {
+		c.err = err
+		c.Invalidate()
+		return
+	}
+	if promise.Ready() {
+		c.appModel.SetActiveView(c.loadingModel.NextViewName())
+	}
+}
+
+func (c *loadingScreenComponent) onRetry() {
+	if retryFunc := c.loadingModel.RetryFunc(); retryFunc != nil {
+		retryFunc()
+	}
+}
+
+func (c *loadingScreenComponent) onQuit() {
+	co.Window(c.Scope()).Close()
+}
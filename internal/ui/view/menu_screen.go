@@ -0,0 +1,442 @@
+package view
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mokiat/ggj2024/internal/game/data"
+	"github.com/mokiat/ggj2024/internal/game/netplay"
+	"github.com/mokiat/ggj2024/internal/ui/controller"
+	"github.com/mokiat/ggj2024/internal/ui/model"
+	"github.com/mokiat/gog/opt"
+	"github.com/mokiat/lacking/debug/log"
+	"github.com/mokiat/lacking/ui"
+	co "github.com/mokiat/lacking/ui/component"
+	"github.com/mokiat/lacking/ui/layout"
+	"github.com/mokiat/lacking/ui/std"
+)
+
+type MenuScreenData struct {
+	AppModel     *model.Application
+	LoadingModel *model.Loading
+	PlayModel    *model.Play
+}
+
+var MenuScreen = co.Define(&menuScreenComponent{})
+
+const (
+	joinTimeout          = 5 * time.Second
+	mouseSensitivityStep = 1.25
+)
+
+type menuScreenComponent struct {
+	co.BaseComponent
+
+	appModel     *model.Application
+	loadingModel *model.Loading
+	playModel    *model.Play
+
+	settings     data.Settings
+	dailyRecords map[string]data.DailyRecord
+
+	networkStatus string
+	connecting    bool
+
+	// hosting is the server that waits for a player to join, after which
+	// the versus game starts.
+	hosting    *netplay.Server
+	hostCancel chan struct{}
+}
+
+var _ ui.ElementKeyboardHandler = (*menuScreenComponent)(nil)
+
+func (c *menuScreenComponent) OnCreate() {
+	screenData := co.GetData[MenuScreenData](c.Properties())
+	c.appModel = screenData.AppModel
+	c.loadingModel = screenData.LoadingModel
+	c.playModel = screenData.PlayModel
+
+	settings, err := data.LoadSettings()
+	if err != nil {
+		log.Warn("Failed to load settings: %v", err)
+	}
+	c.settings = settings
+
+	dailyRecords, err := data.LoadDailyRecords()
+	if err != nil {
+		log.Warn("Failed to load daily records: %v", err)
+	}
+	c.dailyRecords = dailyRecords
+}
+
+func (c *menuScreenComponent) OnDelete() {
+	c.cancelHosting()
+}
+
+func (c *menuScreenComponent) Render() co.Instance {
+	return co.New(std.Container, func() {
+		co.WithData(std.ContainerData{
+			BackgroundColor: opt.V(ui.Black()),
+			Layout:          layout.Anchor(),
+		})
+
+		co.WithChild("content", co.New(std.Element, func() {
+			co.WithLayoutData(layout.Data{
+				HorizontalCenter: opt.V(0),
+				VerticalCenter:   opt.V(0),
+			})
+			co.WithData(std.ElementData{
+				Essence:   c,
+				Focusable: opt.V(true),
+				Focused:   opt.V(true),
+				Layout: layout.Vertical(layout.VerticalSettings{
+					ContentAlignment: layout.HorizontalAlignmentCenter,
+					ContentSpacing:   20,
+				}),
+			})
+
+			co.WithChild("logo", co.New(std.Picture, func() {
+				co.WithLayoutData(layout.Data{
+					Width:  opt.V(512),
+					Height: opt.V(128),
+				})
+				co.WithData(std.PictureData{
+					BackgroundColor: opt.V(ui.Transparent()),
+					Image:           co.OpenImage(c.Scope(), "ui/images/logo.png"),
+					Mode:            std.ImageModeFit,
+				})
+			}))
+
+			for _, info := range controller.GameModes {
+				info := info
+				co.WithChild(info.Name, co.New(std.Element, func() {
+					co.WithData(std.ElementData{
+						Layout: layout.Vertical(layout.VerticalSettings{
+							ContentAlignment: layout.HorizontalAlignmentCenter,
+							ContentSpacing:   5,
+						}),
+					})
+
+					co.WithChild("button", co.New(std.Button, func() {
+						co.WithData(std.ButtonData{
+							Text: info.Title,
+						})
+						co.WithCallbackData(std.ButtonCallbackData{
+							OnClick: func() {
+								c.onSelect(info.Name)
+							},
+						})
+					}))
+
+					co.WithChild("description", co.New(std.Label, func() {
+						co.WithData(std.LabelData{
+							Font:      co.OpenFont(c.Scope(), "ui:///roboto-regular.ttf"),
+							Text:      c.description(info),
+							FontSize:  opt.V(float32(18)),
+							FontColor: opt.V(ui.RGB(0xD9, 0xAD, 0x6C)),
+						})
+					}))
+				}))
+			}
+
+			co.WithChild("online", c.renderOnline())
+			co.WithChild("controls", c.renderControls())
+
+			co.WithChild("achievements", co.New(std.Button, func() {
+				co.WithData(std.ButtonData{
+					Text: "Achievements",
+				})
+				co.WithCallbackData(std.ButtonCallbackData{
+					OnClick: func() {
+						c.appModel.SetActiveView(model.ViewNameAchievements)
+					},
+				})
+			}))
+		}))
+	})
+}
+
+func (c *menuScreenComponent) renderOnline() co.Instance {
+	return co.New(std.Element, func() {
+		co.WithData(std.ElementData{
+			Layout: layout.Vertical(layout.VerticalSettings{
+				ContentAlignment: layout.HorizontalAlignmentCenter,
+				ContentSpacing:   5,
+			}),
+		})
+
+		co.WithChild("address", co.New(std.EditBox, func() {
+			co.WithLayoutData(layout.Data{
+				Width: opt.V(240),
+			})
+			co.WithData(std.EditBoxData{
+				ReadOnly: c.connecting || c.hosting != nil,
+				Text:     c.playModel.NetworkAddress(),
+			})
+			co.WithCallbackData(std.EditBoxCallbackData{
+				OnChange: c.playModel.SetNetworkAddress,
+			})
+		}))
+
+		co.WithChild("buttons", co.New(std.Element, func() {
+			co.WithData(std.ElementData{
+				Layout: layout.Horizontal(layout.HorizontalSettings{
+					ContentSpacing: 10,
+				}),
+			})
+
+			hostText := "Host Online"
+			if c.hosting != nil {
+				hostText = "Stop Hosting"
+			}
+			co.WithChild("host", co.New(std.Button, func() {
+				co.WithData(std.ButtonData{
+					Text: hostText,
+				})
+				co.WithCallbackData(std.ButtonCallbackData{
+					OnClick: c.onHost,
+				})
+			}))
+
+			co.WithChild("join", co.New(std.Button, func() {
+				co.WithData(std.ButtonData{
+					Text: "Join Online",
+				})
+				co.WithCallbackData(std.ButtonCallbackData{
+					OnClick: c.onJoin,
+				})
+			}))
+		}))
+
+		status := "Versus over the network, on the address above."
+		if c.networkStatus != "" {
+			status = c.networkStatus
+		}
+		co.WithChild("status", co.New(std.Label, func() {
+			co.WithData(std.LabelData{
+				Font:      co.OpenFont(c.Scope(), "ui:///roboto-regular.ttf"),
+				Text:      status,
+				FontSize:  opt.V(float32(18)),
+				FontColor: opt.V(ui.RGB(0xD9, 0xAD, 0x6C)),
+			})
+		}))
+	})
+}
+
+func (c *menuScreenComponent) renderControls() co.Instance {
+	controls := c.settings.Controls
+
+	return co.New(std.Element, func() {
+		co.WithData(std.ElementData{
+			Layout: layout.Horizontal(layout.HorizontalSettings{
+				ContentAlignment: layout.VerticalAlignmentCenter,
+				ContentSpacing:   10,
+			}),
+		})
+
+		co.WithChild("mouse-flight", co.New(std.Button, func() {
+			co.WithData(std.ButtonData{
+				Text: fmt.Sprintf("Mouse Flight: %s", onOff(controls.MouseFlight)),
+			})
+			co.WithCallbackData(std.ButtonCallbackData{
+				OnClick: func() {
+					c.updateControls(func(controls *data.ControlSettings) {
+						controls.MouseFlight = !controls.MouseFlight
+					})
+				},
+			})
+		}))
+
+		co.WithChild("invert", co.New(std.Button, func() {
+			co.WithData(std.ButtonData{
+				Text: fmt.Sprintf("Invert: %s", onOff(controls.InvertMouse)),
+			})
+			co.WithCallbackData(std.ButtonCallbackData{
+				OnClick: func() {
+					c.updateControls(func(controls *data.ControlSettings) {
+						controls.InvertMouse = !controls.InvertMouse
+					})
+				},
+			})
+		}))
+
+		co.WithChild("slower", co.New(std.Button, func() {
+			co.WithData(std.ButtonData{
+				Text: "-",
+			})
+			co.WithCallbackData(std.ButtonCallbackData{
+				OnClick: func() {
+					c.updateControls(func(controls *data.ControlSettings) {
+						controls.MouseSensitivity = max(controls.MouseSensitivity/mouseSensitivityStep, data.MinMouseSensitivity)
+					})
+				},
+			})
+		}))
+
+		co.WithChild("sensitivity", co.New(std.Label, func() {
+			co.WithData(std.LabelData{
+				Font:      co.OpenFont(c.Scope(), "ui:///roboto-regular.ttf"),
+				Text:      fmt.Sprintf("Sensitivity %.2f", controls.MouseSensitivity),
+				FontSize:  opt.V(float32(18)),
+				FontColor: opt.V(ui.RGB(0xD9, 0xAD, 0x6C)),
+			})
+		}))
+
+		co.WithChild("faster", co.New(std.Button, func() {
+			co.WithData(std.ButtonData{
+				Text: "+",
+			})
+			co.WithCallbackData(std.ButtonCallbackData{
+				OnClick: func() {
+					c.updateControls(func(controls *data.ControlSettings) {
+						controls.MouseSensitivity = min(controls.MouseSensitivity*mouseSensitivityStep, data.MaxMouseSensitivity)
+					})
+				},
+			})
+		}))
+	})
+}
+
+func (c *menuScreenComponent) updateControls(fn func(controls *data.ControlSettings)) {
+	fn(&c.settings.Controls)
+	if err := data.SaveSettings(c.settings); err != nil {
+		log.Warn("Failed to save settings: %v", err)
+	}
+	c.Invalidate()
+}
+
+func onOff(value bool) string {
+	if value {
+		return "On"
+	}
+	return "Off"
+}
+
+func (c *menuScreenComponent) description(info controller.GameModeInfo) string {
+	if info.Name != controller.GameModeDaily {
+		return info.Description
+	}
+	record, ok := c.dailyRecords[data.NewDailyChallenge(time.Now()).Date]
+	if !ok {
+		return info.Description
+	}
+	return fmt.Sprintf("%s Today's best: %s", info.Description, record.Best.Round(10*time.Millisecond))
+}
+
+func (c *menuScreenComponent) OnKeyboardEvent(element *ui.Element, event ui.KeyboardEvent) bool {
+	if event.Action != ui.KeyboardActionDown {
+		return false
+	}
+	switch event.Code {
+	case ui.KeyCodeEscape:
+		c.onExit()
+		return true
+	case ui.KeyCodeSpace, ui.KeyCodeEnter:
+		c.onSelect(controller.GameModeClassic)
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *menuScreenComponent) onHost() {
+	if c.hosting != nil {
+		c.cancelHosting()
+		c.setNetworkStatus("Stopped hosting.")
+		return
+	}
+	if c.connecting {
+		return
+	}
+	server, err := netplay.Listen(c.playModel.NetworkAddress(), 1, time.Now().UnixNano())
+	if err != nil {
+		log.Error("Failed to host game: %v", err)
+		c.setNetworkStatus(fmt.Sprintf("Cannot host: %v", err))
+		return
+	}
+	cancel := make(chan struct{})
+	c.hosting = server
+	c.hostCancel = cancel
+	c.setNetworkStatus(fmt.Sprintf("Waiting for a player on %s...", server.Addr()))
+
+	scope := c.Scope()
+	go func() {
+		select {
+		case <-server.Joined():
+			co.Schedule(scope, func() {
+				if c.hosting != server {
+					return
+				}
+				c.hosting = nil
+				c.hostCancel = nil
+				c.playModel.SetServer(server)
+				c.startGame(controller.GameModeVersus)
+			})
+		case <-cancel:
+		}
+	}()
+}
+
+func (c *menuScreenComponent) cancelHosting() {
+	if c.hosting == nil {
+		return
+	}
+	close(c.hostCancel)
+	if err := c.hosting.Close(); err != nil {
+		log.Warn("Failed to close server: %v", err)
+	}
+	c.hosting = nil
+	c.hostCancel = nil
+}
+
+func (c *menuScreenComponent) onJoin() {
+	if c.connecting {
+		return
+	}
+	c.cancelHosting()
+	c.connecting = true
+	address := c.playModel.NetworkAddress()
+	c.setNetworkStatus(fmt.Sprintf("Connecting to %s...", address))
+
+	scope := c.Scope()
+	go func() {
+		client, err := netplay.Dial(address, joinTimeout)
+		co.Schedule(scope, func() {
+			c.connecting = false
+			if err != nil {
+				log.Error("Failed to join game: %v", err)
+				c.setNetworkStatus(fmt.Sprintf("Cannot join: %v", err))
+				return
+			}
+			c.playModel.SetClient(client)
+			c.startGame(controller.GameModeVersus)
+		})
+	}()
+}
+
+func (c *menuScreenComponent) setNetworkStatus(status string) {
+	c.networkStatus = status
+	c.Invalidate()
+}
+
+func (c *menuScreenComponent) onSelect(gameMode string) {
+	if c.connecting {
+		return
+	}
+	c.cancelHosting()
+	c.playModel.CloseNetwork()
+	c.startGame(gameMode)
+}
+
+func (c *menuScreenComponent) startGame(gameMode string) {
+	c.playModel.SetGameMode(gameMode)
+	if c.playModel.State() == model.PlayStateLoading {
+		showPlayLoading(c.Scope(), c.appModel, c.loadingModel, c.playModel)
+	} else {
+		startPlayLoading(c.Scope(), c.appModel, c.loadingModel, c.playModel)
+	}
+}
+
+func (c *menuScreenComponent) onExit() {
+	co.Window(c.Scope()).Close()
+}
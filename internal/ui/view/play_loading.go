@@ -0,0 +1,43 @@
+package view
+
+import (
+	"github.com/mokiat/ggj2024/internal/game/data"
+	"github.com/mokiat/ggj2024/internal/ui/global"
+	"github.com/mokiat/ggj2024/internal/ui/model"
+	co "github.com/mokiat/lacking/ui/component"
+)
+
+// startPlayLoading schedules the loading of play data and switches to the
+// loading screen, which proceeds to the play screen once it is done.
+func startPlayLoading(scope co.Scope, appModel *model.Application, loadingModel *model.Loading, playModel *model.Play) {
+	// The screen that triggered the loading may be an overlay that closes
+	// right after, so everything happens in the scope of the application.
+	scope = co.TypedValue[global.AppScope](scope).Scope
+	schedulePlayData(scope, playModel)
+	showPlayLoading(scope, appModel, loadingModel, playModel)
+}
+
+func schedulePlayData(scope co.Scope, playModel *model.Play) {
+	context := co.TypedValue[global.Context](scope)
+	levelName := data.LevelWorld
+	progress := data.NewLoadProgress()
+	promise := context.PlayCache.Acquire(levelName, progress)
+	// The previous level is released only after the new one is acquired,
+	// so that restarting a level reuses its assets.
+	if previous := playModel.LevelName(); previous != "" {
+		context.PlayCache.Release(previous)
+	}
+	// The UI context outlives the screen that triggered the loading, so the
+	// result is dispatched through it rather than through the component.
+	playModel.Load(levelName, promise, progress, scope.Context().Schedule)
+}
+
+func showPlayLoading(scope co.Scope, appModel *model.Application, loadingModel *model.Loading, playModel *model.Play) {
+	scope = co.TypedValue[global.AppScope](scope).Scope
+	loadingModel.SetPromise(playModel.LoadingPromise())
+	loadingModel.SetNextViewName(model.ViewNamePlay)
+	loadingModel.SetRetryFunc(func() {
+		startPlayLoading(scope, appModel, loadingModel, playModel)
+	})
+	appModel.SetActiveView(model.ViewNameLoading)
+}
@@ -2,6 +2,7 @@ package view
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/mokiat/ggj2024/internal/game/data"
@@ -10,6 +11,7 @@ import (
 	"github.com/mokiat/ggj2024/internal/ui/model"
 	"github.com/mokiat/ggj2024/internal/ui/widget"
 	"github.com/mokiat/gog/opt"
+	"github.com/mokiat/lacking/debug/log"
 	"github.com/mokiat/lacking/debug/metric/metricui"
 	"github.com/mokiat/lacking/ui"
 	co "github.com/mokiat/lacking/ui/component"
@@ -34,7 +36,20 @@ type playScreenComponent struct {
 
 	controller *controller.PlayController
 
-	debugVisible bool
+	achievements *controller.AchievementTracker
+	toasts       []achievementToast
+	nextToastID  int
+
+	debugVisible   bool
+	disconnectOpen bool
+	telemetryPath  string
+}
+
+const toastDuration = 4 * time.Second
+
+type achievementToast struct {
+	id          int
+	achievement data.Achievement
 }
 
 var _ ui.ElementKeyboardHandler = (*playScreenComponent)(nil)
@@ -47,26 +62,56 @@ func (c *playScreenComponent) OnCreate() {
 	c.loadingModel = screenData.LoadingModel
 	c.playModel = screenData.PlayModel
 
-	// FIXME: This may actually panic if there is a third party
-	// waiting / reading on this and it happens to match the Get call.
-	promise := screenData.PlayModel.DataPromise()
-	playData, err := promise.Wait()
+	playData := c.playModel.Data()
+	switch state := c.playModel.State(); {
+	case state == model.PlayStateLoading || state == model.PlayStateFailed:
+		// Play data is only ever handed over once loading has finished.
+		// Go back through the loading screen, which reports any failure.
+		co.Schedule(c.Scope(), func() {
+			showPlayLoading(c.Scope(), c.appModel, c.loadingModel, c.playModel)
+		})
+		return
+	case state != model.PlayStateReady || playData == nil:
+		// Nothing is being loaded, so there is nothing to wait for.
+		co.Schedule(c.Scope(), func() {
+			c.appModel.SetActiveView(model.ViewNameMenu)
+		})
+		return
+	}
+	c.controller = controller.NewPlayController(co.Window(c.Scope()).Window, context.AudioAPI, context.Engine, context.Split, playData, controller.NewGameMode(c.playModel.GameMode()))
+	switch server, client := c.playModel.TakeNetwork(); {
+	case server != nil:
+		c.controller.Host(server)
+	case client != nil:
+		c.controller.Join(client)
+	}
+	settings, err := data.LoadSettings()
 	if err != nil {
-		panic(fmt.Errorf("failed to get data: %w", err))
+		log.Warn("Failed to load settings: %v", err)
 	}
-	c.controller = controller.NewPlayController(co.Window(c.Scope()).Window, context.AudioAPI, context.Engine, playData)
+	c.controller.UseControls(settings.Controls)
+	c.controller.OnDisconnect(c.onDisconnect)
+	c.achievements = controller.NewAchievementTracker(c.controller.Events(), data.Achievements, unlockedAchievements(), c.onAchievement)
 	c.controller.Start(c.onVictory, c.onDefeat)
 }
 
 func (c *playScreenComponent) OnDelete() {
-	defer c.controller.Stop()
+	if c.controller != nil {
+		c.controller.Stop()
+	}
 }
 
 func (c *playScreenComponent) OnMouseEvent(element *ui.Element, event ui.MouseEvent) bool {
+	if c.controller == nil {
+		return false
+	}
 	return c.controller.OnMouseEvent(element, event)
 }
 
 func (c *playScreenComponent) OnKeyboardEvent(element *ui.Element, event ui.KeyboardEvent) bool {
+	if c.controller == nil {
+		return false
+	}
 	switch event.Code {
 	case ui.KeyCodeEscape:
 		c.onExit()
@@ -77,12 +122,31 @@ func (c *playScreenComponent) OnKeyboardEvent(element *ui.Element, event ui.Keyb
 			c.Invalidate()
 		}
 		return true
+	case ui.KeyCodeBackspace:
+		if event.Action == ui.KeyboardActionDown {
+			c.controller.Respawn()
+		}
+		return true
+	case ui.KeyCodeR:
+		if event.Action == ui.KeyboardActionDown {
+			c.controller.SoftReset()
+			c.achievements.Reset()
+		}
+		return true
 	default:
 		return c.controller.OnKeyboardEvent(event)
 	}
 }
 
 func (c *playScreenComponent) Render() co.Instance {
+	if c.controller == nil {
+		return co.New(std.Element, func() {
+			co.WithData(std.ElementData{
+				Layout: layout.Anchor(),
+			})
+		})
+	}
+
 	return co.New(std.Element, func() {
 		co.WithData(std.ElementData{
 			Essence:   c,
@@ -102,6 +166,8 @@ func (c *playScreenComponent) Render() co.Instance {
 					Right: opt.V(0),
 				})
 			}))
+
+			co.WithChild("debug-tools", c.renderDebugTools())
 		}
 
 		co.WithChild("lower-border", co.New(std.Container, func() {
@@ -130,28 +196,66 @@ func (c *playScreenComponent) Render() co.Instance {
 			})
 		}))
 
-		co.WithChild("timer", co.New(widget.Timer, func() {
+		co.WithChild("players", co.New(std.Element, func() {
 			co.WithLayoutData(layout.Data{
-				Width:  opt.V(183),
-				Height: opt.V(184),
+				Top:    opt.V(0),
 				Bottom: opt.V(0),
+				Left:   opt.V(0),
 				Right:  opt.V(0),
 			})
-			co.WithData(widget.TimerData{
-				Provider: c.controller,
+			co.WithData(std.ElementData{
+				Layout: widget.SplitLayout(),
 			})
+
+			multiplayer := len(c.controller.Players()) > 1
+			for _, player := range c.controller.LocalPlayers() {
+				co.WithChild(fmt.Sprintf("player-%d", player.Index), c.renderPlayerHUD(player, multiplayer))
+			}
 		}))
 
-		co.WithChild("counter", co.New(widget.CowsCounter, func() {
+		co.WithChild("toasts", co.New(std.Element, func() {
 			co.WithLayoutData(layout.Data{
-				Top:    opt.V(10),
-				Left:   opt.V(10),
-				Width:  opt.V(128),
-				Height: opt.V(184),
+				Top:              opt.V(140),
+				HorizontalCenter: opt.V(0),
 			})
-			co.WithData(widget.CowsCounterData{
-				Provider: c.controller,
+			co.WithData(std.ElementData{
+				Layout: layout.Vertical(layout.VerticalSettings{
+					ContentAlignment: layout.HorizontalAlignmentCenter,
+					ContentSpacing:   10,
+				}),
 			})
+
+			for _, toast := range c.toasts {
+				toast := toast
+				co.WithChild(fmt.Sprintf("toast-%d", toast.id), co.New(std.Container, func() {
+					co.WithData(std.ContainerData{
+						BackgroundColor: opt.V(ui.RGBA(0x00, 0x00, 0x00, 0xA0)),
+						Padding:         ui.UniformSpacing(10),
+						Layout: layout.Vertical(layout.VerticalSettings{
+							ContentAlignment: layout.HorizontalAlignmentCenter,
+							ContentSpacing:   5,
+						}),
+					})
+
+					co.WithChild("title", co.New(std.Label, func() {
+						co.WithData(std.LabelData{
+							Font:      co.OpenFont(c.Scope(), "ui:///roboto-bold.ttf"),
+							Text:      fmt.Sprintf("Achievement unlocked: %s", toast.achievement.Title),
+							FontSize:  opt.V(float32(24)),
+							FontColor: opt.V(ui.White()),
+						})
+					}))
+
+					co.WithChild("description", co.New(std.Label, func() {
+						co.WithData(std.LabelData{
+							Font:      co.OpenFont(c.Scope(), "ui:///roboto-regular.ttf"),
+							Text:      toast.achievement.Description,
+							FontSize:  opt.V(float32(18)),
+							FontColor: opt.V(ui.RGB(0xD9, 0xAD, 0x6C)),
+						})
+					}))
+				}))
+			}
 		}))
 
 		co.WithChild("reset", co.New(widget.ResetButton, func() {
@@ -168,6 +272,163 @@ func (c *playScreenComponent) Render() co.Instance {
 	})
 }
 
+func (c *playScreenComponent) renderDebugTools() co.Instance {
+	return co.New(std.Element, func() {
+		co.WithLayoutData(layout.Data{
+			Bottom: opt.V(120),
+			Left:   opt.V(10),
+		})
+		co.WithData(std.ElementData{
+			Layout: layout.Horizontal(layout.HorizontalSettings{
+				ContentAlignment: layout.VerticalAlignmentCenter,
+				ContentSpacing:   10,
+			}),
+		})
+
+		co.WithChild("physics", co.New(std.Button, func() {
+			co.WithData(std.ButtonData{
+				Text: fmt.Sprintf("Physics: %s", onOff(c.controller.PhysicsDebug())),
+			})
+			co.WithCallbackData(std.ButtonCallbackData{
+				OnClick: c.onTogglePhysicsDebug,
+			})
+		}))
+
+		if c.controller.TelemetryActive() {
+			co.WithChild("stop", co.New(std.Button, func() {
+				co.WithData(std.ButtonData{
+					Text: "Stop Telemetry",
+				})
+				co.WithCallbackData(std.ButtonCallbackData{
+					OnClick: c.onStopTelemetry,
+				})
+			}))
+		} else {
+			co.WithChild("csv", co.New(std.Button, func() {
+				co.WithData(std.ButtonData{
+					Text: "Record CSV",
+				})
+				co.WithCallbackData(std.ButtonCallbackData{
+					OnClick: func() {
+						c.onStartTelemetry(data.TelemetryFormatCSV)
+					},
+				})
+			}))
+
+			co.WithChild("jsonl", co.New(std.Button, func() {
+				co.WithData(std.ButtonData{
+					Text: "Record JSON Lines",
+				})
+				co.WithCallbackData(std.ButtonCallbackData{
+					OnClick: func() {
+						c.onStartTelemetry(data.TelemetryFormatJSONLines)
+					},
+				})
+			}))
+		}
+
+		if c.telemetryPath != "" {
+			co.WithChild("path", co.New(std.Label, func() {
+				co.WithData(std.LabelData{
+					Font:      co.OpenFont(c.Scope(), "ui:///roboto-regular.ttf"),
+					Text:      c.telemetryPath,
+					FontSize:  opt.V(float32(18)),
+					FontColor: opt.V(ui.White()),
+				})
+			}))
+		}
+	})
+}
+
+func (c *playScreenComponent) renderPlayerHUD(player *controller.Player, multiplayer bool) co.Instance {
+	hud := c.controller.HUD()
+
+	return co.New(std.Element, func() {
+		co.WithData(std.ElementData{
+			Layout: layout.Anchor(),
+		})
+
+		if multiplayer {
+			co.WithChild("name", co.New(std.Label, func() {
+				co.WithLayoutData(layout.Data{
+					Top:  opt.V(10),
+					Left: opt.V(150),
+				})
+				co.WithData(std.LabelData{
+					Font:      co.OpenFont(c.Scope(), "ui:///roboto-bold.ttf"),
+					Text:      fmt.Sprintf("Player %d (%s)", player.Index+1, player.Input),
+					FontSize:  opt.V(float32(24)),
+					FontColor: opt.V(ui.White()),
+				})
+			}))
+		}
+
+		if hud.ShowTimer {
+			co.WithChild("timer", co.New(widget.Timer, func() {
+				co.WithLayoutData(layout.Data{
+					Width:  opt.V(183),
+					Height: opt.V(184),
+					Bottom: opt.V(0),
+					Right:  opt.V(0),
+				})
+				co.WithData(widget.TimerData{
+					Provider: player,
+				})
+			}))
+		}
+
+		if hud.ShowCowsCounter {
+			co.WithChild("counter", co.New(widget.CowsCounter, func() {
+				co.WithLayoutData(layout.Data{
+					Top:    opt.V(10),
+					Left:   opt.V(10),
+					Width:  opt.V(128),
+					Height: opt.V(184),
+				})
+				co.WithData(widget.CowsCounterData{
+					Provider: player,
+				})
+			}))
+		}
+
+		co.WithChild("wind", co.New(widget.WindIndicator, func() {
+			co.WithLayoutData(layout.Data{
+				Top:              opt.V(10),
+				HorizontalCenter: opt.V(0),
+				Width:            opt.V(96),
+				Height:           opt.V(120),
+			})
+			co.WithData(widget.WindIndicatorData{
+				Provider: player,
+			})
+		}))
+
+		co.WithChild("bounds", co.New(widget.BoundsWarning, func() {
+			co.WithLayoutData(layout.Data{
+				HorizontalCenter: opt.V(0),
+				VerticalCenter:   opt.V(-120),
+				Width:            opt.V(480),
+				Height:           opt.V(96),
+			})
+			co.WithData(widget.BoundsWarningData{
+				Provider: player,
+			})
+		}))
+
+		co.WithChild("impact", co.New(widget.ImpactMeter, func() {
+			co.WithLayoutData(layout.Data{
+				Bottom:           opt.V(30),
+				HorizontalCenter: opt.V(0),
+				Width:            opt.V(200),
+				Height:           opt.V(24),
+			})
+			co.WithData(widget.ImpactMeterData{
+				Provider: player,
+			})
+		}))
+	})
+}
+
 func (c *playScreenComponent) onExit() {
 	scope := c.Scope()
 	if scope == nil {
@@ -179,15 +440,43 @@ func (c *playScreenComponent) onExit() {
 func (c *playScreenComponent) onVictory(gameTime time.Duration) {
 	c.controller.Freeze()
 
+	if challenge := c.controller.Challenge(); challenge != nil {
+		if _, err := data.RecordDailyResult(challenge.Date, gameTime); err != nil {
+			log.Warn("Failed to record daily result: %v", err)
+		}
+	}
+
 	co.OpenOverlay(c.Scope(), co.New(VictoryScreen, func() {
 		co.WithData(VictoryScreenData{
 			AppModel:     c.appModel,
 			LoadingModel: c.loadingModel,
 			PlayModel:    c.playModel,
+			Message:      c.victoryMessage(),
+			Stats:        c.runStats(),
 		})
 	}))
 }
 
+func (c *playScreenComponent) victoryMessage() string {
+	if c.playModel.GameMode() != controller.GameModeVersus {
+		return ""
+	}
+	if winner := c.controller.Winner(); winner >= 0 {
+		return fmt.Sprintf("Player %d wins!", winner+1)
+	}
+	return "It's a tie!"
+}
+
+// runStats returns the statistics of the players on this machine.
+func (c *playScreenComponent) runStats() []controller.RunStats {
+	players := c.controller.LocalPlayers()
+	stats := make([]controller.RunStats, len(players))
+	for i, player := range players {
+		stats[i] = player.Stats()
+	}
+	return stats
+}
+
 func (c *playScreenComponent) onDefeat(remainingCows int) {
 	c.controller.Freeze()
 
@@ -196,22 +485,99 @@ func (c *playScreenComponent) onDefeat(remainingCows int) {
 			AppModel:     c.appModel,
 			LoadingModel: c.loadingModel,
 			PlayModel:    c.playModel,
+			Stats:        c.runStats(),
 		})
 	}))
 }
 
-func (c *playScreenComponent) onReset() {
+func (c *playScreenComponent) onStartTelemetry(format data.TelemetryFormat) {
+	file, err := data.CreateTelemetryFile(format)
+	if err != nil {
+		log.Error("Failed to start telemetry: %v", err)
+		return
+	}
+	writer, err := data.NewTelemetryWriter(file, format)
+	if err != nil {
+		file.Close()
+		log.Error("Failed to start telemetry: %v", err)
+		return
+	}
+	c.controller.StartTelemetry(writer)
+	c.telemetryPath = file.Name()
+	log.Info("Recording telemetry to %s", c.telemetryPath)
+	c.Invalidate()
+}
+
+func (c *playScreenComponent) onTogglePhysicsDebug() {
+	c.controller.SetPhysicsDebug(!c.controller.PhysicsDebug())
+	c.Invalidate()
+}
+
+func (c *playScreenComponent) onStopTelemetry() {
+	if err := c.controller.StopTelemetry(); err != nil {
+		log.Error("Failed to finish telemetry: %v", err)
+	}
+	c.Invalidate()
+}
+
+func (c *playScreenComponent) onAchievement(achievement data.Achievement) {
+	if err := data.UnlockAchievement(achievement.ID, time.Now()); err != nil {
+		log.Warn("Failed to store achievement: %v", err)
+	}
+
+	c.nextToastID++
+	id := c.nextToastID
+	c.toasts = append(c.toasts, achievementToast{
+		id:          id,
+		achievement: achievement,
+	})
+	c.Invalidate()
+
+	co.After(c.Scope(), toastDuration, func() {
+		c.toasts = slices.DeleteFunc(c.toasts, func(toast achievementToast) bool {
+			return toast.id == id
+		})
+		c.Invalidate()
+	})
+}
+
+func unlockedAchievements() map[string]bool {
+	records, err := data.LoadAchievementRecords()
+	if err != nil {
+		log.Warn("Failed to load achievements: %v", err)
+	}
+	result := make(map[string]bool, len(records))
+	for id := range records {
+		result[id] = true
+	}
+	return result
+}
+
+func (c *playScreenComponent) onDisconnect(player *controller.Player) {
+	if c.disconnectOpen {
+		return // the open prompt covers all players
+	}
+	c.disconnectOpen = true
 	c.controller.Freeze()
 
-	context := co.TypedValue[global.Context](c.Scope())
-	audioAPI := context.AudioAPI
-	engine := context.Engine
-	resourceSet := context.ResourceSet
+	co.OpenOverlay(c.Scope(), co.New(DisconnectScreen, func() {
+		co.WithData(DisconnectScreenData{
+			Controller: c.controller,
+			Player:     player,
+		})
+		co.WithCallbackData(DisconnectScreenCallbackData{
+			OnResume: c.onResume,
+		})
+	}))
+}
 
-	c.playModel.SetDataPromise(data.LoadPlayData(audioAPI, engine, resourceSet))
+func (c *playScreenComponent) onResume() {
+	c.disconnectOpen = false
+	c.controller.Unfreeze()
+}
+
+func (c *playScreenComponent) onReset() {
+	c.controller.Freeze()
 
-	promise := c.playModel.DataPromise()
-	c.loadingModel.SetPromise(model.ToLoadingPromise(promise))
-	c.loadingModel.SetNextViewName(model.ViewNamePlay)
-	c.appModel.SetActiveView(model.ViewNameLoading)
+	startPlayLoading(c.Scope(), c.appModel, c.loadingModel, c.playModel)
 }
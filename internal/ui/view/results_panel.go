@@ -0,0 +1,154 @@
+package view
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mokiat/ggj2024/internal/ui/controller"
+	"github.com/mokiat/ggj2024/internal/ui/widget"
+	"github.com/mokiat/gog/opt"
+	"github.com/mokiat/lacking/ui"
+	co "github.com/mokiat/lacking/ui/component"
+	"github.com/mokiat/lacking/ui/layout"
+	"github.com/mokiat/lacking/ui/std"
+)
+
+// resultsPanelWidth and resultsPanelHeight are the size that the victory
+// and defeat screens reserve for the results panel of one player.
+const (
+	resultsPanelWidth  = 520
+	resultsPanelHeight = 260
+)
+
+// resultsTitleHeight is the extra height of a results panel that names
+// its player, as is the case with more than one player on this machine.
+const resultsTitleHeight = 30
+
+// bannerHeight is the height of the victory and defeat images.
+const bannerHeight = 273
+
+var ResultsPanel = co.Define(&resultsPanelComponent{})
+
+type ResultsPanelData struct {
+	// Title is shown above the results, if specified.
+	Title string
+	Stats controller.RunStats
+}
+
+type resultsPanelComponent struct {
+	co.BaseComponent
+}
+
+func (c *resultsPanelComponent) Render() co.Instance {
+	data := co.GetData[ResultsPanelData](c.Properties())
+	stats := data.Stats
+
+	score := fmt.Sprintf("%d", stats.Score)
+	if stats.RequiredScore > 0 {
+		score = fmt.Sprintf("%d / %d", stats.Score, stats.RequiredScore)
+	}
+	if stats.Share != stats.Score {
+		score = fmt.Sprintf("%s (own %d)", score, stats.Share)
+	}
+	lines := []string{
+		fmt.Sprintf("Time: %s", stats.Time.Round(10*time.Millisecond)),
+		fmt.Sprintf("Score: %s", score),
+		fmt.Sprintf("Cows popped: %d", stats.Pops),
+		fmt.Sprintf("Max speed: %.0f m/s", stats.MaxSpeed),
+		fmt.Sprintf("Max altitude: %.0f m", stats.MaxAltitude),
+		fmt.Sprintf("Distance flown: %.1f km", stats.Distance/1000.0),
+		fmt.Sprintf("Cows rubbed: %d", stats.Rubs),
+		fmt.Sprintf("Crashes: %d", stats.Crashes),
+	}
+	if data.Title != "" {
+		lines = slices.Insert(lines, 0, data.Title)
+	}
+
+	return co.New(std.Container, func() {
+		co.WithLayoutData(c.Properties().LayoutData())
+		co.WithData(std.ContainerData{
+			BackgroundColor: opt.V(ui.RGBA(0x00, 0x00, 0x00, 0xC0)),
+			Padding:         ui.UniformSpacing(10),
+			Layout: layout.Horizontal(layout.HorizontalSettings{
+				ContentAlignment: layout.VerticalAlignmentCenter,
+				ContentSpacing:   20,
+			}),
+		})
+
+		co.WithChild("lines", co.New(std.Element, func() {
+			co.WithLayoutData(layout.Data{
+				Width: opt.V(240),
+			})
+			co.WithData(std.ElementData{
+				Layout: layout.Vertical(layout.VerticalSettings{
+					ContentSpacing: 4,
+				}),
+			})
+
+			for i, line := range lines {
+				line := line
+				co.WithChild(fmt.Sprintf("line-%d", i), co.New(std.Label, func() {
+					co.WithData(std.LabelData{
+						Font:      co.OpenFont(c.Scope(), "ui:///roboto-regular.ttf"),
+						Text:      line,
+						FontSize:  opt.V(float32(20)),
+						FontColor: opt.V(ui.White()),
+					})
+				}))
+			}
+		}))
+
+		co.WithChild("path", co.New(widget.FlightPath, func() {
+			co.WithLayoutData(layout.Data{
+				Width:  opt.V(240),
+				Height: opt.V(240),
+			})
+			co.WithData(widget.FlightPathData{
+				Points: stats.Path,
+			})
+		}))
+	})
+}
+
+// resultsWidth returns the width of a screen that shows the results of
+// the specified number of players side by side.
+func resultsWidth(count int) int {
+	return resultsPanelWidth * max(1, count)
+}
+
+// resultsHeight returns the height that the results of the specified
+// number of players need below the banner.
+func resultsHeight(count int) int {
+	switch count {
+	case 0:
+		return 0
+	case 1:
+		return resultsPanelHeight
+	default:
+		return resultsPanelHeight + resultsTitleHeight
+	}
+}
+
+// withResults adds a results panel for each of the specified stats below
+// the banner of a victory or defeat screen.
+func withResults(stats []controller.RunStats) {
+	for i, playerStats := range stats {
+		var title string
+		if len(stats) > 1 {
+			title = fmt.Sprintf("Player %d", i+1)
+		}
+		co.WithChild(fmt.Sprintf("results-%d", i), co.New(ResultsPanel, func() {
+			co.WithLayoutData(layout.Data{
+				Top:    opt.V(bannerHeight),
+				Bottom: opt.V(0),
+				Left:   opt.V(i * resultsPanelWidth),
+				Width:  opt.V(resultsPanelWidth),
+			})
+			co.WithData(ResultsPanelData{
+				Title: title,
+				Stats: playerStats,
+			})
+		}))
+	}
+}
@@ -1,8 +1,7 @@
 package view
 
 import (
-	"github.com/mokiat/ggj2024/internal/game/data"
-	"github.com/mokiat/ggj2024/internal/ui/global"
+	"github.com/mokiat/ggj2024/internal/ui/controller"
 	"github.com/mokiat/ggj2024/internal/ui/model"
 	"github.com/mokiat/ggj2024/internal/ui/widget"
 	"github.com/mokiat/gog/opt"
@@ -18,6 +17,13 @@ type VictoryScreenData struct {
 	AppModel     *model.Application
 	LoadingModel *model.Loading
 	PlayModel    *model.Play
+
+	// Message is shown below the victory banner, if specified.
+	Message string
+
+	// Stats are shown in results panels below the banner, one for each
+	// player on this machine.
+	Stats []controller.RunStats
 }
 
 var _ ui.ElementMouseHandler = (*victoryScreenComponent)(nil)
@@ -29,6 +35,8 @@ type victoryScreenComponent struct {
 	appModel     *model.Application
 	loadingModel *model.Loading
 	playModel    *model.Play
+	message      string
+	stats        []controller.RunStats
 }
 
 func (c *victoryScreenComponent) OnCreate() {
@@ -36,13 +44,15 @@ func (c *victoryScreenComponent) OnCreate() {
 	c.appModel = data.AppModel
 	c.loadingModel = data.LoadingModel
 	c.playModel = data.PlayModel
+	c.message = data.Message
+	c.stats = data.Stats
 }
 
 func (c *victoryScreenComponent) Render() co.Instance {
 	return co.New(widget.Modal, func() {
 		co.WithLayoutData(layout.Data{
-			Width:            opt.V(520),
-			Height:           opt.V(273),
+			Width:            opt.V(resultsWidth(len(c.stats))),
+			Height:           opt.V(bannerHeight + resultsHeight(len(c.stats))),
 			HorizontalCenter: opt.V(0),
 			VerticalCenter:   opt.V(0),
 		})
@@ -51,16 +61,39 @@ func (c *victoryScreenComponent) Render() co.Instance {
 				Essence:   c,
 				Focusable: opt.V(true),
 				Focused:   opt.V(true),
-				Layout:    layout.Fill(),
+				Layout:    layout.Anchor(),
 			})
 
 			co.WithChild("image", co.New(std.Picture, func() {
+				co.WithLayoutData(layout.Data{
+					Top:    opt.V(0),
+					Left:   opt.V(0),
+					Right:  opt.V(0),
+					Height: opt.V(bannerHeight),
+				})
 				co.WithData(std.PictureData{
 					Image:      co.OpenImage(c.Scope(), "ui/images/victory.png"),
 					ImageColor: opt.V(ui.White()),
 					Mode:       std.ImageModeStretch,
 				})
 			}))
+
+			if c.message != "" {
+				co.WithChild("message", co.New(std.Label, func() {
+					co.WithLayoutData(layout.Data{
+						Top:              opt.V(bannerHeight - 56),
+						HorizontalCenter: opt.V(0),
+					})
+					co.WithData(std.LabelData{
+						Font:      co.OpenFont(c.Scope(), "ui:///roboto-bold.ttf"),
+						Text:      c.message,
+						FontSize:  opt.V(float32(32)),
+						FontColor: opt.V(ui.White()),
+					})
+				}))
+			}
+
+			withResults(c.stats)
 		}))
 	})
 }
@@ -86,6 +119,9 @@ func (c *victoryScreenComponent) OnKeyboardEvent(element *ui.Element, event ui.K
 		case ui.KeyCodeSpace, ui.KeyCodeEnter:
 			c.onContinue()
 			return true
+		case ui.KeyCodeM:
+			c.onMenu()
+			return true
 		default:
 			return false
 		}
@@ -93,18 +129,14 @@ func (c *victoryScreenComponent) OnKeyboardEvent(element *ui.Element, event ui.K
 	return false
 }
 
-func (c *victoryScreenComponent) onContinue() {
+func (c *victoryScreenComponent) onMenu() {
 	co.CloseOverlay(c.Scope())
 
-	context := co.TypedValue[global.Context](c.Scope())
-	audioAPI := context.AudioAPI
-	engine := context.Engine
-	resourceSet := context.ResourceSet
+	c.appModel.SetActiveView(model.ViewNameMenu)
+}
 
-	c.playModel.SetDataPromise(data.LoadPlayData(audioAPI, engine, resourceSet))
+func (c *victoryScreenComponent) onContinue() {
+	co.CloseOverlay(c.Scope())
 
-	promise := c.playModel.DataPromise()
-	c.loadingModel.SetPromise(model.ToLoadingPromise(promise))
-	c.loadingModel.SetNextViewName(model.ViewNamePlay)
-	c.appModel.SetActiveView(model.ViewNameLoading)
+	startPlayLoading(c.Scope(), c.appModel, c.loadingModel, c.playModel)
 }
@@ -0,0 +1,92 @@
+package widget
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mokiat/gog/opt"
+	"github.com/mokiat/gomath/sprec"
+	"github.com/mokiat/lacking/ui"
+	co "github.com/mokiat/lacking/ui/component"
+	"github.com/mokiat/lacking/ui/layout"
+	"github.com/mokiat/lacking/ui/std"
+)
+
+type BoundsProvider interface {
+	OutOfBounds() (time.Duration, bool)
+}
+
+var BoundsWarning = co.Define(&boundsWarningComponent{})
+
+type BoundsWarningData struct {
+	Provider BoundsProvider
+}
+
+type boundsWarningComponent struct {
+	co.BaseComponent
+
+	provider BoundsProvider
+
+	font *ui.Font
+}
+
+func (c *boundsWarningComponent) OnCreate() {
+	data := co.GetData[BoundsWarningData](c.Properties())
+	c.provider = data.Provider
+
+	c.font = co.OpenFont(c.Scope(), "ui:///roboto-bold.ttf")
+}
+
+func (c *boundsWarningComponent) Render() co.Instance {
+	return co.New(std.Element, func() {
+		co.WithLayoutData(c.Properties().LayoutData())
+		co.WithData(std.ElementData{
+			Essence:   c,
+			Layout:    layout.Anchor(),
+			IdealSize: opt.V(ui.NewSize(480, 96)),
+		})
+	})
+}
+
+func (c *boundsWarningComponent) OnRender(element *ui.Element, canvas *ui.Canvas) {
+	defer element.Invalidate()
+
+	remaining, outside := c.provider.OutOfBounds()
+	if !outside {
+		return
+	}
+
+	drawBounds := canvas.DrawBounds(element, false)
+	canvas.Reset()
+	canvas.Rectangle(
+		drawBounds.Position,
+		drawBounds.Size,
+	)
+	canvas.Fill(ui.Fill{
+		Color: ui.RGBA(0x8B, 0x00, 0x00, 0xB0),
+	})
+
+	title := []rune("Return to the area!")
+	titleSize := float32(32.0)
+	canvas.Reset()
+	canvas.FillTextLine(title, sprec.Vec2{
+		X: drawBounds.Position.X + (drawBounds.Width()-c.font.LineWidth(title, titleSize))/2,
+		Y: drawBounds.Position.Y + 10.0,
+	}, ui.Typography{
+		Font:  c.font,
+		Size:  titleSize,
+		Color: ui.White(),
+	})
+
+	countdown := []rune(fmt.Sprintf("%d", int(remaining.Seconds()+0.999)))
+	countdownSize := float32(36.0)
+	canvas.Reset()
+	canvas.FillTextLine(countdown, sprec.Vec2{
+		X: drawBounds.Position.X + (drawBounds.Width()-c.font.LineWidth(countdown, countdownSize))/2,
+		Y: drawBounds.Position.Y + 48.0,
+	}, ui.Typography{
+		Font:  c.font,
+		Size:  countdownSize,
+		Color: ui.RGB(0xD9, 0xAD, 0x6C),
+	})
+}
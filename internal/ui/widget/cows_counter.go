@@ -12,7 +12,7 @@ import (
 )
 
 type CowProvider interface {
-	CowsRemaining() int
+	CowCount() int
 }
 
 var CowsCounter = co.Define(&cowsCounterComponent{})
@@ -63,7 +63,7 @@ func (c *cowsCounterComponent) OnRender(element *ui.Element, canvas *ui.Canvas)
 		ImageSize:   drawBounds.Size,
 	})
 
-	text := []rune(fmt.Sprintf("%d", c.provider.CowsRemaining()))
+	text := []rune(fmt.Sprintf("%d", c.provider.CowCount()))
 	fontSize := float32(24.0)
 
 	canvas.Reset()
@@ -0,0 +1,94 @@
+package widget
+
+import (
+	"github.com/mokiat/gog/opt"
+	"github.com/mokiat/gomath/dprec"
+	"github.com/mokiat/gomath/sprec"
+	"github.com/mokiat/lacking/ui"
+	co "github.com/mokiat/lacking/ui/component"
+	"github.com/mokiat/lacking/ui/std"
+)
+
+var FlightPath = co.Define(&flightPathComponent{})
+
+// FlightPathData holds the horizontal positions of a flight, as seen
+// from above.
+type FlightPathData struct {
+	Points []dprec.Vec2
+}
+
+type flightPathComponent struct {
+	co.BaseComponent
+
+	points []dprec.Vec2
+}
+
+func (c *flightPathComponent) OnUpsert() {
+	data := co.GetData[FlightPathData](c.Properties())
+	c.points = data.Points
+}
+
+func (c *flightPathComponent) Render() co.Instance {
+	return co.New(std.Element, func() {
+		co.WithLayoutData(c.Properties().LayoutData())
+		co.WithData(std.ElementData{
+			Essence:   c,
+			IdealSize: opt.V(ui.NewSize(240, 240)),
+		})
+	})
+}
+
+func (c *flightPathComponent) OnRender(element *ui.Element, canvas *ui.Canvas) {
+	const padding = 12.0
+
+	drawBounds := canvas.DrawBounds(element, false)
+	canvas.Reset()
+	canvas.Rectangle(drawBounds.Position, drawBounds.Size)
+	canvas.Fill(ui.Fill{
+		Color: ui.RGBA(0xD9, 0xAD, 0x6C, 0x40),
+	})
+	if len(c.points) < 2 {
+		return
+	}
+
+	minPoint, maxPoint := c.points[0], c.points[0]
+	for _, point := range c.points[1:] {
+		minPoint = dprec.NewVec2(min(minPoint.X, point.X), min(minPoint.Y, point.Y))
+		maxPoint = dprec.NewVec2(max(maxPoint.X, point.X), max(maxPoint.Y, point.Y))
+	}
+	// The plot keeps the proportions of the flight and is centered.
+	extent := max(maxPoint.X-minPoint.X, maxPoint.Y-minPoint.Y, 1.0)
+	available := min(drawBounds.Width(), drawBounds.Height()) - 2*padding
+	scale := float32(float64(available) / extent)
+	center := dprec.Vec2Prod(dprec.Vec2Sum(minPoint, maxPoint), 0.5)
+	origin := sprec.Vec2Sum(drawBounds.Position, sprec.Vec2Prod(drawBounds.Size, 0.5))
+	project := func(point dprec.Vec2) sprec.Vec2 {
+		// The X axis points to the left when looking from above with Z
+		// pointing up on the screen.
+		return sprec.NewVec2(
+			origin.X-float32(point.X-center.X)*scale,
+			origin.Y-float32(point.Y-center.Y)*scale,
+		)
+	}
+
+	canvas.Reset()
+	canvas.SetStrokeSize(2.0)
+	canvas.SetStrokeColor(ui.White())
+	canvas.MoveTo(project(c.points[0]))
+	for _, point := range c.points[1:] {
+		canvas.LineTo(project(point))
+	}
+	canvas.Stroke()
+
+	canvas.Reset()
+	canvas.Circle(project(c.points[0]), 5.0)
+	canvas.Fill(ui.Fill{
+		Color: ui.RGB(0x40, 0xC0, 0x40),
+	})
+
+	canvas.Reset()
+	canvas.Circle(project(c.points[len(c.points)-1]), 5.0)
+	canvas.Fill(ui.Fill{
+		Color: ui.RGB(0xC0, 0x40, 0x40),
+	})
+}
@@ -0,0 +1,84 @@
+package widget
+
+import (
+	"github.com/mokiat/gog/opt"
+	"github.com/mokiat/gomath/sprec"
+	"github.com/mokiat/lacking/ui"
+	co "github.com/mokiat/lacking/ui/component"
+	"github.com/mokiat/lacking/ui/layout"
+	"github.com/mokiat/lacking/ui/std"
+)
+
+type ImpactProvider interface {
+	ImpactStrength() float64
+}
+
+var ImpactMeter = co.Define(&impactMeterComponent{})
+
+type ImpactMeterData struct {
+	Provider ImpactProvider
+}
+
+type impactMeterComponent struct {
+	co.BaseComponent
+
+	provider ImpactProvider
+}
+
+func (c *impactMeterComponent) OnCreate() {
+	data := co.GetData[ImpactMeterData](c.Properties())
+	c.provider = data.Provider
+}
+
+func (c *impactMeterComponent) Render() co.Instance {
+	return co.New(std.Element, func() {
+		co.WithLayoutData(c.Properties().LayoutData())
+		co.WithData(std.ElementData{
+			Essence:   c,
+			Layout:    layout.Anchor(),
+			IdealSize: opt.V(ui.NewSize(200, 24)),
+		})
+	})
+}
+
+func (c *impactMeterComponent) OnRender(element *ui.Element, canvas *ui.Canvas) {
+	drawBounds := canvas.DrawBounds(element, false)
+
+	canvas.Reset()
+	canvas.Rectangle(
+		drawBounds.Position,
+		drawBounds.Size,
+	)
+	canvas.Fill(ui.Fill{
+		Color: ui.RGBA(0x00, 0x00, 0x00, 0x80),
+	})
+
+	// A strength of 1.0 is what is needed to hit a cow, which is drawn
+	// at the middle of the meter.
+	strength := float32(c.provider.ImpactStrength()) / 2.0
+	if strength > 0.0 {
+		color := ui.RGB(0xD9, 0xAD, 0x6C)
+		if strength >= 0.5 {
+			color = ui.RGB(0xE0, 0x40, 0x30)
+		}
+		canvas.Reset()
+		canvas.Rectangle(
+			drawBounds.Position,
+			sprec.NewVec2(drawBounds.Width()*min(strength, 1.0), drawBounds.Height()),
+		)
+		canvas.Fill(ui.Fill{
+			Color: color,
+		})
+	}
+
+	canvas.Reset()
+	canvas.Rectangle(
+		sprec.NewVec2(drawBounds.X()+drawBounds.Width()/2.0-1.0, drawBounds.Y()),
+		sprec.NewVec2(2.0, drawBounds.Height()),
+	)
+	canvas.Fill(ui.Fill{
+		Color: ui.White(),
+	})
+
+	element.Invalidate()
+}
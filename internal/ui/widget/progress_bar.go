@@ -0,0 +1,69 @@
+package widget
+
+import (
+	"github.com/mokiat/gog/opt"
+	"github.com/mokiat/gomath/sprec"
+	"github.com/mokiat/lacking/ui"
+	co "github.com/mokiat/lacking/ui/component"
+	"github.com/mokiat/lacking/ui/layout"
+	"github.com/mokiat/lacking/ui/std"
+)
+
+type ProgressProvider interface {
+	Progress() float64
+}
+
+var ProgressBar = co.Define(&progressBarComponent{})
+
+type ProgressBarData struct {
+	Provider ProgressProvider
+}
+
+type progressBarComponent struct {
+	co.BaseComponent
+
+	provider ProgressProvider
+}
+
+func (c *progressBarComponent) OnUpsert() {
+	data := co.GetData[ProgressBarData](c.Properties())
+	c.provider = data.Provider
+}
+
+func (c *progressBarComponent) Render() co.Instance {
+	return co.New(std.Element, func() {
+		co.WithLayoutData(c.Properties().LayoutData())
+		co.WithData(std.ElementData{
+			Essence:   c,
+			Layout:    layout.Anchor(),
+			IdealSize: opt.V(ui.NewSize(400, 16)),
+		})
+	})
+}
+
+func (c *progressBarComponent) OnRender(element *ui.Element, canvas *ui.Canvas) {
+	drawBounds := canvas.DrawBounds(element, false)
+
+	canvas.Reset()
+	canvas.Rectangle(
+		drawBounds.Position,
+		drawBounds.Size,
+	)
+	canvas.Fill(ui.Fill{
+		Color: ui.RGB(0x8B, 0x63, 0x28),
+	})
+
+	progress := float32(c.provider.Progress())
+	if progress > 0.0 {
+		canvas.Reset()
+		canvas.Rectangle(
+			drawBounds.Position,
+			sprec.NewVec2(drawBounds.Width()*min(progress, 1.0), drawBounds.Height()),
+		)
+		canvas.Fill(ui.Fill{
+			Color: ui.RGB(0xD9, 0xAD, 0x6C),
+		})
+	}
+
+	element.Invalidate()
+}
@@ -0,0 +1,29 @@
+package widget
+
+import "github.com/mokiat/lacking/ui"
+
+// SplitLayout returns a layout that stacks the children of an element from
+// top to bottom, giving each of them an equal share of the height. This
+// matches how the game splits the screen between players.
+func SplitLayout() ui.Layout {
+	return splitLayout{}
+}
+
+type splitLayout struct{}
+
+func (splitLayout) Apply(element *ui.Element) {
+	count := 0
+	for child := element.FirstChild(); child != nil; child = child.RightSibling() {
+		count++
+	}
+	if count == 0 {
+		return
+	}
+	bounds := element.ContentBounds()
+	height := bounds.Height / count
+	index := 0
+	for child := element.FirstChild(); child != nil; child = child.RightSibling() {
+		child.SetBounds(ui.NewBounds(bounds.X, bounds.Y+index*height, bounds.Width, height))
+		index++
+	}
+}
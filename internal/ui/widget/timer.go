@@ -13,7 +13,7 @@ import (
 )
 
 type TimeProvider interface {
-	RemainingTime() time.Duration
+	DisplayTime() time.Duration
 }
 
 var Timer = co.Define(&timerComponent{})
@@ -64,9 +64,9 @@ func (c *timerComponent) OnRender(element *ui.Element, canvas *ui.Canvas) {
 		ImageSize:   drawBounds.Size,
 	})
 
-	remainingTime := c.provider.RemainingTime().Truncate(time.Second)
-	minutes := int(remainingTime.Seconds()) / 60
-	seconds := int(remainingTime.Seconds()) % 60
+	displayTime := c.provider.DisplayTime().Truncate(time.Second)
+	minutes := int(displayTime.Seconds()) / 60
+	seconds := int(displayTime.Seconds()) % 60
 
 	text := []rune(fmt.Sprintf("%02d:%02d", minutes, seconds))
 	fontSize := float32(32.0)
@@ -0,0 +1,104 @@
+package widget
+
+import (
+	"fmt"
+
+	"github.com/mokiat/gog/opt"
+	"github.com/mokiat/gomath/dprec"
+	"github.com/mokiat/gomath/sprec"
+	"github.com/mokiat/lacking/ui"
+	co "github.com/mokiat/lacking/ui/component"
+	"github.com/mokiat/lacking/ui/layout"
+	"github.com/mokiat/lacking/ui/std"
+)
+
+type WindProvider interface {
+	// WindDirection returns the direction the wind blows towards, relative
+	// to the direction of flight. Positive angles are to the left.
+	WindDirection() dprec.Angle
+
+	WindSpeed() float64
+}
+
+var WindIndicator = co.Define(&windIndicatorComponent{})
+
+type WindIndicatorData struct {
+	Provider WindProvider
+}
+
+type windIndicatorComponent struct {
+	co.BaseComponent
+
+	provider WindProvider
+
+	font *ui.Font
+}
+
+func (c *windIndicatorComponent) OnCreate() {
+	data := co.GetData[WindIndicatorData](c.Properties())
+	c.provider = data.Provider
+
+	c.font = co.OpenFont(c.Scope(), "ui:///roboto-bold.ttf")
+}
+
+func (c *windIndicatorComponent) Render() co.Instance {
+	return co.New(std.Element, func() {
+		co.WithLayoutData(c.Properties().LayoutData())
+		co.WithData(std.ElementData{
+			Essence:   c,
+			Layout:    layout.Anchor(),
+			IdealSize: opt.V(ui.NewSize(96, 120)),
+		})
+	})
+}
+
+func (c *windIndicatorComponent) OnRender(element *ui.Element, canvas *ui.Canvas) {
+	drawBounds := canvas.DrawBounds(element, false)
+	radius := drawBounds.Width() / 2.0
+	center := sprec.Vec2Sum(drawBounds.Position, sprec.NewVec2(radius, radius))
+
+	canvas.Reset()
+	canvas.Circle(center, radius)
+	canvas.Fill(ui.Fill{
+		Color: ui.RGBA(0xD9, 0xAD, 0x6C, 0xC0),
+	})
+
+	angle := sprec.Radians(float32(c.provider.WindDirection().Radians()))
+	direction := sprec.NewVec2(-sprec.Sin(angle), -sprec.Cos(angle))
+	side := sprec.NewVec2(-direction.Y, direction.X)
+	tip := sprec.Vec2Sum(center, sprec.Vec2Prod(direction, radius*0.8))
+	tail := sprec.Vec2Diff(center, sprec.Vec2Prod(direction, radius*0.6))
+	head := sprec.Vec2Diff(tip, sprec.Vec2Prod(direction, radius*0.35))
+
+	canvas.Reset()
+	canvas.SetStrokeSize(4.0)
+	canvas.SetStrokeColor(ui.RGB(0x8B, 0x63, 0x28))
+	canvas.MoveTo(tail)
+	canvas.LineTo(head)
+	canvas.Stroke()
+
+	canvas.Reset()
+	canvas.Triangle(
+		tip,
+		sprec.Vec2Sum(head, sprec.Vec2Prod(side, radius*0.25)),
+		sprec.Vec2Diff(head, sprec.Vec2Prod(side, radius*0.25)),
+	)
+	canvas.Fill(ui.Fill{
+		Color: ui.RGB(0x8B, 0x63, 0x28),
+	})
+
+	text := []rune(fmt.Sprintf("%.0f m/s", c.provider.WindSpeed()))
+	fontSize := float32(20.0)
+
+	canvas.Reset()
+	canvas.FillTextLine(text, sprec.Vec2{
+		X: center.X - c.font.LineWidth(text, fontSize)/2,
+		Y: drawBounds.Position.Y + 2.0*radius + 4.0,
+	}, ui.Typography{
+		Font:  c.font,
+		Size:  fontSize,
+		Color: ui.White(),
+	})
+
+	element.Invalidate()
+}
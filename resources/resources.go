@@ -7,3 +7,6 @@ var UI embed.FS
 
 //go:embed sound
 var Sound embed.FS
+
+//go:embed gameplay
+var Gameplay embed.FS